@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	utils "github.com/kalidor/traggo_cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// tags []string // already declared
+	// note string   // already declared
+	addStartStr  string
+	addEndStr    string
+	allowOverlap bool
+
+	// addCmd represents the add command
+	addCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Add an already done task",
+		Long: `Add a task with explicit start and end dates, without running a timer.
+The task must not overlap existing ones, unless --overlap is given:
+
+- traggo_cli add -t project:cli --start "yesterday 14:00" --end "yesterday 16:30"
+- traggo_cli add -t project:cli -n "Review" --start 9:00 --end 10:15`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			start, err := utils.ParseDate(addStartStr, now)
+			if err != nil {
+				return err
+			}
+			end, err := utils.ParseDate(addEndStr, now)
+			if err != nil {
+				return err
+			}
+			if !end.After(start) {
+				return errors.New("the end date must be after the start date")
+			}
+			if end.After(now) {
+				return errors.New("the end date cannot be in the future, use start instead")
+			}
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			s := session.NewTraggoSession(c)
+			ctx := cmd.Context()
+			task := session.TimeSpanTask{
+				TimerTask: session.TimerTask{Start: start, Tags: session.ParseTags(tags), Note: note},
+				End:       end,
+			}
+			err = checkOverlap(ctx, s, task)
+			if err != nil {
+				return err
+			}
+			task, err = s.CreateTimeSpanContext(ctx, task)
+			if err != nil && !errors.Is(err, session.ErrQueued) {
+				return err
+			}
+			pErr := printTask(c, task)
+			if pErr != nil {
+				return pErr
+			}
+			return handleQueued(err)
+		},
+	}
+)
+
+// checkOverlap fails if task overlaps existing tasks, unless --overlap is
+// given. Traggo being unreachable is not an error: the creation is queued.
+func checkOverlap(ctx context.Context, s *session.Traggo, task session.TimeSpanTask) error {
+	if allowOverlap {
+		return nil
+	}
+	err := s.CheckOverlapContext(ctx, task)
+	if errors.Is(err, session.ErrOverlap) {
+		return fmt.Errorf("%w (use --overlap to allow it)", err)
+	}
+	if errors.Is(err, session.ErrUnreachable) {
+		fmt.Fprintln(os.Stderr, "Traggo is unreachable: overlaps are not checked")
+		return nil
+	}
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+	addCmd.Flags().StringArrayVarP(&tags, "tags", "t", []string{}, "List of tags")
+	addCmd.Flags().StringVarP(&note, "note", "n", "", "Note associated to this task")
+	addCmd.Flags().StringVar(&addStartStr, "start", "", "Start date: "+utils.DateHelp)
+	addCmd.Flags().StringVar(&addEndStr, "end", "", "End date: "+utils.DateHelp)
+	addCmd.Flags().BoolVar(&allowOverlap, "overlap", false, "Allow overlapping existing tasks")
+	addCmd.MarkFlagRequired("tags")
+	addCmd.MarkFlagRequired("start")
+	addCmd.MarkFlagRequired("end")
+}
@@ -48,12 +48,15 @@ var (
 				return err
 			}
 
-			token, err := session.RequestPermanentTokenAndTest(url, login, string(bytePassword))
+			token, err := session.RequestPermanentTokenAndTestContext(cmd.Context(), url, login, string(bytePassword))
 			if err != nil {
 				return err
 			}
 			fmt.Println("Ping success!")
-			config.NewConfig(url, token).Save(configPath)
+			err = config.NewConfig(url, token).Save(configPath)
+			if err != nil {
+				return err
+			}
 			fmt.Println("You can edit the configuration file to add some color by tagName:tagValue")
 			return nil
 		},
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/spf13/cobra"
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup [file]",
+	Short: "Save the whole account into a JSON archive",
+	Long: `Save time spans, running timers, tags with their colors, user settings and dashboards
+into a single JSON archive, written to stdout without file or with '-'.
+The archive can be replayed into an empty account with 'traggo_cli restore'. Examples:
+- traggo_cli backup traggo-$(date +%F).json
+- traggo_cli backup | gzip > traggo.json.gz`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := config.LoadConfig(configPath)
+		if err != nil {
+			return err
+		}
+		s := session.NewTraggoSession(c)
+		archive, err := s.BackupContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 || args[0] == "-" {
+			err = archive.Write(os.Stdout)
+			if err != nil {
+				return err
+			}
+		} else {
+			f, err := os.OpenFile(args[0], os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+			if err != nil {
+				return err
+			}
+			err = archive.Write(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		}
+		// the archive may be on stdout
+		fmt.Fprintf(os.Stderr, "%d time span(s), %d timer(s), %d tag(s), %d dashboard(s) saved\n",
+			len(archive.TimeSpans), len(archive.Timers), len(archive.Tags), len(archive.Dashboards))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+}
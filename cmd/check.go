@@ -13,15 +13,18 @@ var (
 	testCmd = &cobra.Command{
 		Use:   "check",
 		Short: "Check API connectivity with current token",
-		Run: func(cmd *cobra.Command, args []string) {
-			c := config.LoadConfig(configPath)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
 			s := session.NewTraggoSession(c)
-			err := s.Ping()
+			err = s.PingContext(cmd.Context())
 			if err != nil {
-				fmt.Println("Unable to request the API", err)
-				return
+				return fmt.Errorf("unable to request the API: %w", err)
 			}
 			fmt.Println("Ping success!")
+			return nil
 		},
 	}
 )
@@ -1,55 +1,93 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
+	"strings"
 
 	config "github.com/kalidor/traggo_cli/config"
 	session "github.com/kalidor/traggo_cli/session"
+	"github.com/kalidor/traggo_cli/tui"
 	"github.com/spf13/cobra"
 )
 
-// continueCmd represents the continue command
-var continueCmd = &cobra.Command{
-	Use:   "continue",
-	Short: "continue a previous task",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return errors.New("this command requiers one task_id or TagName:TagValue")
-		}
-		c := config.LoadConfig(configPath)
-		s := session.NewTraggoSession(c)
-		var task session.GenericTask
-		re := regexp.MustCompile(`(?P<TagName>[[:word:]]*):(?P<TagValue>[a-zA-Z_\-0-9]+)`)
-		matches := re.FindStringSubmatch(args[0])
-		if len(matches) > 0 {
-			// Let's look by 'TagName & TagValue'
-			nIndex := re.SubexpIndex("TagName")
-			tagName := matches[nIndex]
-			tIndex := re.SubexpIndex("TagValue")
-			tagValue := matches[tIndex]
+var (
+	// tags []string // already declared
+	// note string   // already declared
+
+	// continueCmd represents the continue command
+	continueCmd = &cobra.Command{
+		Use:   "continue [id | TagName:TagValue...]",
+		Short: "continue a previous task",
+		Long: `Start a new timer copying a task given by id, or the latest time span having
+all the given tags (any value if TagValue is empty). Tags given with --tags replace
+the ones with the same name, --note replaces the note:
 
-			task = s.SearchTaskByTag(tagName, tagValue)
-		} else {
-			// Let's look for this id in all tasks
-			argInt, err := strconv.Atoi(args[0])
+- traggo_cli continue 12
+- traggo_cli continue project:foo ticket:
+- traggo_cli continue project:foo -t ticket:42 -n "Review"
+Without argument, the task to continue is picked among the recent ones.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := config.LoadConfig(configPath)
 			if err != nil {
 				return err
 			}
-			task = s.SearchTask(argInt)
+			s := session.NewTraggoSession(c)
+			ctx := cmd.Context()
+			var task session.GenericTask
+			if len(args) == 0 {
+				picked, err := tui.PickTasks(ctx, s, session.TaskFilter{}, false)
+				if err != nil || len(picked) == 0 {
+					return err
+				}
+				task = picked[0]
+			} else {
+				task, err = findContinued(ctx, s, args)
+				if err != nil {
+					return err
+				}
+			}
+			if task == nil {
+				fmt.Println("Unable to retrieve the requested id / tag")
+				return nil
+			}
+			started, err := s.ContinueWithContext(ctx, task, tags, note)
+			if err != nil && !errors.Is(err, session.ErrQueued) {
+				return err
+			}
+			pErr := printTask(c, started)
+			if pErr != nil {
+				return pErr
+			}
+			return handleQueued(err)
+		},
+	}
+)
+
+// findContinued returns the task given by id, or the latest time span
+// having all the tags of args
+func findContinued(ctx context.Context, s *session.Traggo, args []string) (session.GenericTask, error) {
+	if id, err := strconv.Atoi(strings.TrimSpace(args[0])); err == nil {
+		if len(args) > 1 {
+			return nil, errors.New("a task id cannot be combined with other filters")
 		}
-		if task == nil {
-			fmt.Println("Unable to retrieve the requested id / tag")
-			return nil
+		return s.SearchTaskContext(ctx, id)
+	}
+	var filter session.TaskFilter
+	for _, arg := range args {
+		key, value, ok := strings.Cut(strings.TrimSpace(arg), ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid task id or TagName:TagValue '%s'", arg)
 		}
-		s.Continue(task)
-		// TODO: show freshly created continued task
-		return nil
-	},
+		filter.Tags = append(filter.Tags, session.Tag{Key: key, Value: value})
+	}
+	return s.LatestTimeSpanContext(ctx, filter)
 }
 
 func init() {
 	rootCmd.AddCommand(continueCmd)
+	continueCmd.Flags().StringArrayVarP(&tags, "tags", "t", []string{}, "Tags replacing the ones with the same name")
+	continueCmd.Flags().StringVarP(&note, "note", "n", "", "Note replacing the one of the task")
 }
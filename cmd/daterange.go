@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	utils "github.com/kalidor/traggo_cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// addDateRangeFlags registers the flags read by dateRange
+func addDateRangeFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(
+		&today,
+		"today",
+		"t",
+		false, // default value
+		"Today tasks",
+	)
+	cmd.Flags().StringVarP(
+		&startDateStr,
+		"start-date",
+		"s",
+		"", // default value
+		"Start date of the tasks ("+utils.DateHelp+"). To use with -end-date/-e",
+	)
+	cmd.Flags().StringVarP(
+		&endDateStr,
+		"end-date",
+		"e",
+		"",
+		"End date of the tasks ("+utils.DateHelp+"). To use with -start-date/-s",
+	)
+	cmd.Flags().StringVarP(
+		&period,
+		"period",
+		"p",
+		"",
+		"Period of time of the tasks (1d= 1day, 1m=1 month). To be used with --month or --start-date´",
+	)
+}
+
+// dateRange converts --start-date, --end-date, --period and --today flags
+// to a date range. Both dates are zero if no flag is provided.
+func dateRange() (time.Time, time.Time, error) {
+	var (
+		endDate   time.Time
+		startDate time.Time
+		err       error
+	)
+
+	delta := func(sDate time.Time, eDate *time.Time) {}
+
+	if period != "" {
+		re := regexp.MustCompile(`(?P<Number>(?:-)?\d+)(?P<Type>[[:alpha:]]{1})`)
+		matches := re.FindStringSubmatch(period)
+		if len(matches) > 0 {
+			nIndex := re.SubexpIndex("Number")
+			nString := matches[nIndex]
+			number, _ := strconv.Atoi(nString)
+			tIndex := re.SubexpIndex("Type")
+
+			c := matches[tIndex]
+			switch c {
+			case "d":
+				delta = func(sDate time.Time, eDate *time.Time) {
+					*eDate = sDate.AddDate(0, 0, number)
+				}
+
+			case "m":
+				delta = func(sDate time.Time, eDate *time.Time) {
+					*eDate = sDate.AddDate(0, number, 0)
+				}
+
+			case "w":
+				delta = func(sDate time.Time, eDate *time.Time) {
+					*eDate = sDate.AddDate(0, 0, number*7)
+				}
+			default:
+				return startDate, endDate, fmt.Errorf("invalid period provided: '%s'", period)
+			}
+		}
+	}
+
+	if startDateStr != "" {
+		startDate, err = utils.ParseDate(startDateStr, time.Now())
+		if err != nil {
+			return startDate, endDate, err
+		}
+
+		if period != "" {
+			delta(startDate, &endDate)
+		}
+	}
+
+	if endDateStr != "" {
+		endDate, err = utils.ParseDate(endDateStr, time.Now())
+		if err != nil {
+			return startDate, endDate, err
+		}
+
+		if period != "" {
+			delta(startDate, &endDate)
+		}
+	}
+
+	if today {
+		startDate, endDate = todayRange()
+		return startDate, endDate, nil
+	}
+
+	if startDate.IsZero() && endDate.IsZero() {
+		if period == "" {
+			return startDate, endDate, nil
+		}
+		endDate = time.Now()
+		// period is negative number
+		delta(endDate, &startDate)
+	}
+
+	if !startDate.IsZero() && period == "" && endDate.IsZero() {
+		endDate = time.Now()
+	}
+	return startDate, endDate, nil
+}
+
+// todayRange returns the range from the beginning of today until now
+func todayRange() (time.Time, time.Time) {
+	now := time.Now()
+	startDate, _ := utils.ParseDate("today", now)
+	return startDate, now
+}
+
+// requiredDateRange is dateRange defaulting to today when no flag is provided
+func requiredDateRange() (time.Time, time.Time, error) {
+	startDate, endDate, err := dateRange()
+	if err != nil {
+		return startDate, endDate, err
+	}
+	if startDate.IsZero() && endDate.IsZero() {
+		startDate, endDate = todayRange()
+		return startDate, endDate, nil
+	}
+	if startDate.IsZero() || endDate.IsZero() {
+		return startDate, endDate, errors.New("both start and end dates are required")
+	}
+	return startDate, endDate, nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat   string
+	exportFile     string
+	summaryTags    []string
+	includeRunning bool
+
+	// exportCmd represents the export command
+	exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export tasks to other tools",
+		Long: `Export tasks between two dates. Without date, today is exported.
+With the ics format, each task is a calendar event whose summary is made of the
+values of --summary-tags (export.summaryTags in configuration file). Examples:
+- traggo_cli export --format ics --period -1m > timesheet.ics
+- traggo_cli export -s 2025-08-01 -e 2025-08-31 --summary-tags project,type --file august.ics
+- traggo_cli export --today --include-running`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if exportFormat != "ics" {
+				return fmt.Errorf("unsupported export format '%s', expected ics", exportFormat)
+			}
+			startDate, endDate, err := requiredDateRange()
+			if err != nil {
+				return err
+			}
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			s := session.NewTraggoSession(c)
+			ctx := cmd.Context()
+
+			spans, err := s.ListBetweenDatesContext(ctx, startDate, endDate)
+			if err != nil {
+				return err
+			}
+			var timers session.TimersData
+			if includeRunning {
+				timers, err = s.ListCurrentTasksStartingAtContext(ctx, startDate)
+				if err != nil {
+					return err
+				}
+			}
+
+			opts := session.ICSOptions{SummaryTags: c.Export.SummaryTags}
+			if cmd.Flags().Changed("summary-tags") {
+				opts.SummaryTags = summaryTags
+			}
+			u, err := url.Parse(c.Auth.Url)
+			if err == nil {
+				opts.Host = u.Hostname()
+			}
+			ics := session.ToICS(timers, spans, opts)
+
+			if exportFile == "" {
+				fmt.Print(ics)
+				return nil
+			}
+			return os.WriteFile(exportFile, []byte(ics), 0o644)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	addDateRangeFlags(exportCmd)
+	exportCmd.Flags().StringVar(&exportFormat, "format", "ics", "Export format, only ics for now")
+	exportCmd.Flags().StringVarP(&exportFile, "file", "f", "", "Write to this file instead of stdout")
+	exportCmd.Flags().StringSliceVar(&summaryTags, "summary-tags", []string{}, "Tag names whose values make the event summary (all tags if empty)")
+	exportCmd.Flags().BoolVar(&includeRunning, "include-running", false, "Export running tasks as tentative events ending now")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	session "github.com/kalidor/traggo_cli/session"
+	utils "github.com/kalidor/traggo_cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	filterTag  string
+	filterNote string
+	filterFrom string
+	filterTo   string
+)
+
+// addFilterFlags registers the flags used to select tasks
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&filterTag, "filter-tag", "", "Select tasks with this tag (TagName or TagName:TagValue)")
+	cmd.Flags().StringVar(&filterNote, "filter-note", "", "Select tasks whose note contains this string (case insensitive)")
+	cmd.Flags().StringVar(&filterFrom, "from", "", "Select tasks started from this date: "+utils.DateHelp)
+	cmd.Flags().StringVar(&filterTo, "to", "", "Select tasks started until this date: "+utils.DateHelp)
+}
+
+// hasFilter tells if at least one filter flag has been provided
+func hasFilter() bool {
+	return filterTag != "" || filterNote != "" || filterFrom != "" || filterTo != ""
+}
+
+// parseFilterDate accepts the forms of utils.ParseDate. A day or a week for
+// the end of the range is included as a whole.
+func parseFilterDate(s string, endOfDay bool) (time.Time, error) {
+	start, end, err := utils.ParseDatePeriod(s, time.Now())
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay && end.After(start) {
+		return end.Add(-time.Nanosecond), nil
+	}
+	return start, nil
+}
+
+// buildFilter converts filter flags to a session.TaskFilter
+func buildFilter() (session.TaskFilter, error) {
+	var filter session.TaskFilter
+	var err error
+	if filterTag != "" {
+		key, value, _ := strings.Cut(filterTag, ":")
+		filter.TagKey, filter.TagValue = key, value
+	}
+	filter.Note = filterNote
+	if filterFrom != "" {
+		filter.From, err = parseFilterDate(filterFrom, false)
+		if err != nil {
+			return filter, err
+		}
+	}
+	if filterTo != "" {
+		filter.To, err = parseFilterDate(filterTo, true)
+		if err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+// findTask returns the task designated by arg: a task id, or TagName:TagValue
+// for the newest task with this tag. A nil GenericTask is returned if nothing matches.
+func findTask(ctx context.Context, s *session.Traggo, arg string) (session.GenericTask, error) {
+	filter := session.TaskFilter{Limit: 1}
+	re := regexp.MustCompile(`^(?P<TagName>[[:word:]]*):(?P<TagValue>[a-zA-Z_\-0-9]+)$`)
+	matches := re.FindStringSubmatch(strings.TrimSpace(arg))
+	if len(matches) > 0 {
+		filter.TagKey = matches[re.SubexpIndex("TagName")]
+		filter.TagValue = matches[re.SubexpIndex("TagValue")]
+	} else {
+		id, err := strconv.Atoi(strings.TrimSpace(arg))
+		if err != nil {
+			return nil, fmt.Errorf("invalid task id or TagName:TagValue '%s'", arg)
+		}
+		filter.Id = id
+	}
+	tasks, err := s.SearchTasksContext(ctx, filter)
+	if err != nil || len(tasks) == 0 {
+		return nil, err
+	}
+	return tasks[0], nil
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat string
+	dryRun       bool
+	startTimers  bool
+	importMap    []string
+	startColumn  string
+	endColumn    string
+	noteColumn   string
+	tagsColumn   string
+
+	// importCmd represents the import command
+	importCmd = &cobra.Command{
+		Use:   "import file",
+		Short: "Import time spans from CSV or JSON files",
+		Long: `Import time spans from a CSV or JSON file ('-' for stdin).
+The csv and json formats read 'start', 'end', 'note' and 'tags' (TagName:TagValue separated by ';')
+columns by default, as written by 'traggo_cli list --output csv|json'.
+Other columns become tags with --map column=TagName.
+toggl and clockify read the detailed CSV exports of these tools.
+Rows without end are running timers, as saved by 'traggo_cli rm': they are
+skipped, unless --start-timers is given to start them.
+Time spans with the same start, end, tags and note as an existing one, and timers
+with the same start, tags and note as a running one, are skipped. Examples:
+- traggo_cli import --dry-run history.csv
+- traggo_cli import --format toggl Toggl_time_entries.csv
+- traggo_cli import --start-column begin --note-column comment --map client=customer export.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := importFormat
+			if format == "" {
+				format = session.ImportCSV
+				if strings.EqualFold(filepath.Ext(args[0]), ".json") {
+					format = session.ImportJSON
+				}
+			}
+			mapping := session.DefaultImportMapping()
+			if startColumn != "" {
+				mapping.Start = startColumn
+			}
+			if endColumn != "" {
+				mapping.End = endColumn
+			}
+			if noteColumn != "" {
+				mapping.Note = noteColumn
+			}
+			if tagsColumn != "" {
+				mapping.Tags = tagsColumn
+			}
+			for _, m := range importMap {
+				column, tagName, ok := strings.Cut(m, "=")
+				if !ok || column == "" || tagName == "" {
+					return fmt.Errorf("invalid mapping '%s', expected column=TagName", m)
+				}
+				mapping.Columns[column] = tagName
+			}
+
+			var r io.Reader = os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			entries, err := session.ParseImport(r, format, mapping)
+			if err != nil {
+				return err
+			}
+
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			s := session.NewTraggoSession(c)
+			report, err := s.ImportContext(cmd.Context(), entries, dryRun, startTimers)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				printInfo("Dry run, nothing has been created\n")
+			}
+			if !report.Created.IsEmpty() {
+				var timers session.TimersData
+				var spans session.TimeSpanTaskList
+				for _, span := range report.Created {
+					if span.End.IsZero() {
+						timers.Timers = append(timers.Timers, span.TimerTask)
+					} else {
+						spans = append(spans, span)
+					}
+				}
+				err = printTasks(c, timers, spans)
+				if err != nil {
+					return err
+				}
+			}
+			for _, e := range report.Duplicates {
+				if e.Span.End.IsZero() {
+					printInfo("line %d: duplicate of a running timer (%s)\n", e.Line, e.Span.GetStartString())
+					continue
+				}
+				printInfo("line %d: duplicate of an existing time span (%s -> %s)\n", e.Line, e.Span.GetStartString(), e.Span.GetStopString())
+			}
+			for _, e := range report.Running {
+				printInfo("line %d: running timer skipped (%s), use --start-timers to start it\n", e.Line, e.Span.GetStartString())
+			}
+			for _, e := range report.Failures {
+				fmt.Fprintf(os.Stderr, "line %d: %s\n", e.Line, e.Err)
+			}
+			action := "created"
+			if dryRun {
+				action = "to create"
+			}
+			printInfo("%d %s, %d queued, %d duplicate(s), %d running timer(s) skipped, %d failure(s)\n", len(report.Created), action, len(report.Queued), len(report.Duplicates), len(report.Running), len(report.Failures))
+			if len(report.Queued) > 0 {
+				handleQueued(session.ErrQueued)
+			}
+			if len(report.Failures) > 0 {
+				return errors.New("some lines have not been imported")
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importFormat, "format", "", "File format: csv, json, toggl or clockify (default from file extension)")
+	importCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be imported without creating anything")
+	importCmd.Flags().BoolVar(&startTimers, "start-timers", false, "Start the timers of the rows without end")
+	importCmd.Flags().StringArrayVar(&importMap, "map", []string{}, "Use a column as tag: column=TagName")
+	importCmd.Flags().StringVar(&startColumn, "start-column", "", "Column of the start date (default 'start')")
+	importCmd.Flags().StringVar(&endColumn, "end-column", "", "Column of the end date (default 'end')")
+	importCmd.Flags().StringVar(&noteColumn, "note-column", "", "Column of the note (default 'note')")
+	importCmd.Flags().StringVar(&tagsColumn, "tags-column", "", "Column of the TagName:TagValue list (default 'tags')")
+}
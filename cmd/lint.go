@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	utils "github.com/kalidor/traggo_cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintWorkHours string
+	lintGap       time.Duration
+	lintMaxTimer  time.Duration
+	lintFix       bool
+	lintYes       bool
+
+	// lintCmd represents the lint command
+	lintCmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Find overlapping, forgotten or missing tasks",
+		Long: `Look for suspicious tasks in a date range, today by default:
+- overlapping time spans
+- gaps between time spans of a day, during working hours
+- time spans ending before or when they start
+- timers running for too long
+
+Defaults come from the lint section of the configuration file. With --fix,
+each fix is proposed: the first span ends when the second one starts, start and
+end are swapped, a long timer is stopped. Fixes are reverted with 'traggo_cli undo'.
+
+- traggo_cli lint -s monday
+- traggo_cli lint -s 2025-W05 --gap 30m --work-hours 08:30-17:30
+- traggo_cli lint --fix`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startDate, endDate, err := requiredDateRange()
+			if err != nil {
+				return err
+			}
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			opts, err := lintOptions(cmd, c.Lint)
+			if err != nil {
+				return err
+			}
+			s := session.NewTraggoSession(c)
+			ctx := cmd.Context()
+
+			issues, err := s.LintBetweenContext(ctx, startDate, endDate, opts)
+			if err != nil {
+				return err
+			}
+			if len(issues) == 0 {
+				printInfo("No issue found\n")
+				return nil
+			}
+			switch outputFormat {
+			case outputJSON:
+				err = writeJSON(os.Stdout, issues.Records())
+			case outputCSV, outputTSV:
+				err = writeRows(os.Stdout, issues.Header(), issues.Rows())
+			default:
+				fmt.Println(issues.PreparePretty(c.Colors))
+			}
+			if err != nil || !lintFix {
+				return err
+			}
+
+			fixed, queued := 0, false
+			for _, issue := range issues {
+				if issue.Fix == nil {
+					continue
+				}
+				if !lintYes {
+					r, err := utils.AskAndCompare(fmt.Sprintf("%s of %s: %s (y/N): ", issue.Kind, lintIds(issue), issue.Fix.Description), "y")
+					if err != nil {
+						return err
+					}
+					if !r {
+						continue
+					}
+				}
+				err := s.ApplyLintFixContext(ctx, *issue.Fix)
+				if errors.Is(err, session.ErrQueued) {
+					queued = true
+				} else if err != nil {
+					return fmt.Errorf("%s of %s: %w", issue.Kind, lintIds(issue), err)
+				}
+				fixed++
+			}
+			printInfo("%d issue(s) fixed\n", fixed)
+			if queued {
+				return handleQueued(session.ErrQueued)
+			}
+			return nil
+		},
+	}
+)
+
+// lintIds describes the tasks of issue
+func lintIds(issue session.LintIssue) string {
+	ids := make([]string, len(issue.Tasks))
+	for i, task := range issue.Tasks {
+		ids[i] = fmt.Sprintf("%d", task.GetId())
+	}
+	return "task " + strings.Join(ids, " and ")
+}
+
+// lintOptions reads the flags, defaulting to the configuration then to the
+// default values
+func lintOptions(cmd *cobra.Command, def config.LintDef) (session.LintOptions, error) {
+	workHours := def.WorkHours
+	if cmd.Flags().Changed("work-hours") || workHours == "" {
+		workHours = lintWorkHours
+	}
+	start, end, ok := strings.Cut(workHours, "-")
+	if !ok {
+		return session.LintOptions{}, fmt.Errorf("invalid working hours '%s', expected hh:mm-hh:mm", workHours)
+	}
+	var opts session.LintOptions
+	var err error
+	opts.WorkStart, err = utils.ParseClock(start)
+	if err != nil {
+		return opts, err
+	}
+	opts.WorkEnd, err = utils.ParseClock(end)
+	if err != nil {
+		return opts, err
+	}
+	if opts.WorkEnd <= opts.WorkStart {
+		return opts, fmt.Errorf("invalid working hours '%s', the end must be after the start", workHours)
+	}
+
+	opts.Gap = lintGap
+	if !cmd.Flags().Changed("gap") && def.Gap.Duration != 0 {
+		opts.Gap = def.Gap.Duration
+	}
+	opts.MaxTimer = lintMaxTimer
+	if !cmd.Flags().Changed("max-timer") && def.MaxTimer.Duration != 0 {
+		opts.MaxTimer = def.MaxTimer.Duration
+	}
+	return opts, nil
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	addDateRangeFlags(lintCmd)
+	lintCmd.Flags().StringVar(&lintWorkHours, "work-hours", config.DefaultWorkHours, "Working hours, gaps are only looked for inside")
+	lintCmd.Flags().DurationVar(&lintGap, "gap", config.DefaultLintGap, "Shortest gap reported, 0 to ignore gaps")
+	lintCmd.Flags().DurationVar(&lintMaxTimer, "max-timer", config.DefaultMaxTimer, "Timers running longer are reported, 0 to ignore them")
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "Propose to apply the fixes")
+	lintCmd.Flags().BoolVar(&lintYes, "yes", false, "Apply all the fixes without confirmation, with --fix")
+}
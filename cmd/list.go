@@ -1,14 +1,10 @@
 package cmd
 
 import (
-	"fmt"
-	"regexp"
-	"strconv"
 	"time"
 
 	config "github.com/kalidor/traggo_cli/config"
 	session "github.com/kalidor/traggo_cli/session"
-	utils "github.com/kalidor/traggo_cli/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -27,122 +23,55 @@ var (
 - ./traggo_cli list [-s | --start-date 2025-08-12] [-e | --end-date 2025-08-20]
 - ./traggo_cli list --period -1m # the same as below
 - ./traggo_cli list -s 2025-07-22 -e 2025-08-22 # if today is 2025-08-22
-- ./traggo_cli list --period 1w`,
+- ./traggo_cli list --period 1w
+- ./traggo_cli list --today --output json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c := config.LoadConfig(configPath)
-			s := session.NewTraggoSession(c)
-
-			var (
-				endDate   time.Time
-				startDate time.Time
-				err       error
-			)
-
-			delta := func(sDate time.Time, eDate *time.Time) {}
-
-			if period != "" {
-				re := regexp.MustCompile(`(?P<Number>(?:-)?\d+)(?P<Type>[[:alpha:]]{1})`)
-				matches := re.FindStringSubmatch(period)
-				if len(matches) > 0 {
-					nIndex := re.SubexpIndex("Number")
-					nString := matches[nIndex]
-					number, _ := strconv.Atoi(nString)
-					tIndex := re.SubexpIndex("Type")
-
-					c := matches[tIndex]
-					switch c {
-					case "d":
-						delta = func(sDate time.Time, eDate *time.Time) {
-							*eDate = sDate.AddDate(0, 0, number)
-						}
-
-					case "m":
-						delta = func(sDate time.Time, eDate *time.Time) {
-							*eDate = sDate.AddDate(0, number, 0)
-						}
-
-					case "w":
-						delta = func(sDate time.Time, eDate *time.Time) {
-							*eDate = sDate.AddDate(0, 0, number*7)
-						}
-					default:
-						return fmt.Errorf("invalid period provided: '%s'", period)
-					}
-				}
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
 			}
+			s := session.NewTraggoSession(c)
+			ctx := cmd.Context()
 
-			if startDateStr != "" {
-				startDate, err = utils.StrToTime(startDateStr, time.DateOnly)
-				if err != nil {
-					return err
-				}
-
-				if period != "" {
-					delta(startDate, &endDate)
-				}
-				fmt.Println(startDate)
+			startDate, endDate, err := dateRange()
+			if err != nil {
+				return err
 			}
 
-			if endDateStr != "" {
-				endDate, err = utils.StrToTime(endDateStr, time.DateOnly)
+			if startDate.IsZero() && endDate.IsZero() {
+				// if there is no parameter, display current tasks
+				tasks, err := s.ListCurrentTasksContext(ctx)
 				if err != nil {
 					return err
 				}
-
-				if period != "" {
-					delta(startDate, &endDate)
-				}
-			}
-
-			if startDate.IsZero() && endDate.IsZero() && !today {
-				if period == "" {
-					// if there is no parameter, display current tasks
-					tasks := s.ListCurrentTasks()
-					if tasks.IsEmpty() {
-						return nil
-					}
-					fmt.Println(tasks.PreparePretty(c.Colors, highlight))
-				} else {
-					endDate = time.Now()
-					// period is negative number
-					delta(endDate, &startDate)
-				}
+				return printTasks(c, tasks, nil)
 			}
 			if today {
-				tmp := time.Now().Format(time.DateOnly)
-				startDate, _ = utils.StrToTime(tmp, time.DateOnly)
 				// Done tasks
-				fmt.Printf("Date range: [%s -> %s]\n", startDate.Format(time.DateOnly), startDate.Format(time.DateOnly))
-				doneTasks := s.ListBetweenDates(startDate, time.Now())
-				if doneTasks.IsEmpty() {
-					return nil
+				printInfo("Date range: [%s -> %s]\n", startDate.Format(time.DateOnly), startDate.Format(time.DateOnly))
+				doneTasks, err := s.ListBetweenDatesContext(ctx, startDate, endDate)
+				if err != nil {
+					return err
 				}
-				fmt.Println(doneTasks.PreparePretty(c.Colors, highlight))
-
-				tasks := s.ListCurrentTasks()
-				if tasks.IsEmpty() {
-					return nil
+				tasks, err := s.ListCurrentTasksContext(ctx)
+				if err != nil {
+					return err
 				}
-				fmt.Println(tasks.PreparePretty(c.Colors, highlight))
-				return nil
-			}
-
-			if !startDate.IsZero() && period == "" && endDate.IsZero() {
-				endDate = time.Now()
+				return printTasks(c, tasks, doneTasks)
 			}
 
 			if !startDate.IsZero() && !endDate.IsZero() {
-				fmt.Printf("Date range: [%s -> %s]\n", startDate.Format(time.DateOnly), endDate.Format(time.DateOnly))
+				printInfo("Date range: [%s -> %s]\n", startDate.Format(time.DateOnly), endDate.Format(time.DateOnly))
 
-				startedTasks := s.ListCurrentTasksStartingAt(startDate)
-				if !startedTasks.IsEmpty() {
-					fmt.Println(startedTasks.PreparePretty(c.Colors, highlight))
+				startedTasks, err := s.ListCurrentTasksStartingAtContext(ctx, startDate)
+				if err != nil {
+					return err
 				}
-				tasks := s.ListBetweenDates(startDate, endDate)
-				if tasks.IsEmpty() {
-					return nil
+				tasks, err := s.ListBetweenDatesContext(ctx, startDate, endDate)
+				if err != nil {
+					return err
 				}
-				fmt.Println(tasks.PreparePretty(c.Colors, highlight))
+				return printTasks(c, startedTasks, tasks)
 			}
 
 			return nil
@@ -153,34 +82,7 @@ var (
 
 func init() {
 	rootCmd.AddCommand(listCmd)
-	listCmd.Flags().BoolVarP(
-		&today,
-		"today",
-		"t",
-		false, // default value
-		"List today tasks",
-	)
-	listCmd.Flags().StringVarP(
-		&startDateStr,
-		"start-date",
-		"s",
-		"", // default value
-		"Start date to list tasks. To use with -end-date/-e",
-	)
-	listCmd.Flags().StringVarP(
-		&endDateStr,
-		"end-date",
-		"e",
-		"",
-		"End date to list tasks. To use with -start-date/-s",
-	)
-	listCmd.Flags().StringVarP(
-		&period,
-		"period",
-		"p",
-		"",
-		"Period of time to list task (1d= 1day, 1m=1 month). To be used with --month or --start-date´",
-	)
+	addDateRangeFlags(listCmd)
 	listCmd.Flags().StringVarP(
 		&highlight,
 		"Highlight",
@@ -1,13 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	config "github.com/kalidor/traggo_cli/config"
 	session "github.com/kalidor/traggo_cli/session"
 	"github.com/kalidor/traggo_cli/tui"
+	utils "github.com/kalidor/traggo_cli/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -15,32 +18,55 @@ import (
 var liveCmd = &cobra.Command{
 	Use:   "live",
 	Short: "Live dashboard useful to interact with traggo",
-	Run: func(cmd *cobra.Command, args []string) {
-		c := config.LoadConfig(configPath)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := config.LoadConfig(configPath)
+		if err != nil {
+			return err
+		}
 		s := session.NewTraggoSession(c)
-		err := s.CheckTagsInConfig()
-		// TODO: add command to force tag creation
+		err = createMissingTags(cmd.Context(), s)
 		if err != nil {
-			panic(err)
+			return err
 		}
 
 		var dump *os.File
 		if _, ok := os.LookupEnv("DEBUG"); ok {
-			var err error
 			dump, err = os.OpenFile("messages.log", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
 			if err != nil {
-				os.Exit(1)
+				return err
 			}
 		}
 		m, _ := tui.NewMainModel(dump, s, tui.TableView)
 
 		if _, err := tea.NewProgram(m).Run(); err != nil {
-			fmt.Println("Error running program:", err)
-			os.Exit(1)
+			return fmt.Errorf("error running program: %w", err)
 		}
+		return nil
 	},
 }
 
+// createMissingTags offers to create the tags of the configuration unknown by Traggo
+func createMissingTags(ctx context.Context, s *session.Traggo) error {
+	missing, err := s.MissingTagsContext(ctx)
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+	r, err := utils.AskAndCompare(fmt.Sprintf("Unknown tags: %s. Create them (y/N): ", strings.Join(missing, ",")), "y")
+	if err != nil {
+		return err
+	}
+	if !r {
+		return fmt.Errorf("unknown tags: %s, create them with 'traggo_cli tag create'", strings.Join(missing, ","))
+	}
+	for _, key := range missing {
+		_, err := s.CreateTagContext(ctx, key, session.TagColor(key))
+		if err != nil {
+			return fmt.Errorf("tag '%s': %w", key, err)
+		}
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(liveCmd)
 
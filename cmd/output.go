@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+	outputTSV   = "tsv"
+)
+
+var outputFormat string
+
+func checkOutputFormat() error {
+	switch outputFormat {
+	case outputTable, outputJSON, outputCSV, outputTSV:
+		return nil
+	}
+	return fmt.Errorf("invalid output format '%s', expected json, csv, tsv or table", outputFormat)
+}
+
+// printInfo prints informative messages. They go to stderr with machine
+// readable outputs, to keep stdout parsable.
+func printInfo(format string, a ...any) {
+	w := io.Writer(os.Stdout)
+	if outputFormat != outputTable {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, format, a...)
+}
+
+// printTasks prints current running tasks then already done tasks
+func printTasks(c *config.Config, timers session.TimersData, spans session.TimeSpanTaskList) error {
+	if outputFormat == outputTable {
+		if !timers.IsEmpty() {
+			fmt.Println(timers.PreparePretty(c.Colors, highlight))
+		}
+		if !spans.IsEmpty() {
+			fmt.Println(spans.PreparePretty(c.Colors, highlight))
+		}
+		return nil
+	}
+	return writeRecords(os.Stdout, append(timers.Records(), spans.Records()...))
+}
+
+// printTaskList prints each task, in a list for machine readable outputs
+func printTaskList(c *config.Config, tasks []session.GenericTask) error {
+	if outputFormat == outputTable {
+		for _, task := range tasks {
+			fmt.Println(task.PreparePretty(c.Colors))
+		}
+		return nil
+	}
+	records := make([]session.Record, 0, len(tasks))
+	for _, task := range tasks {
+		records = append(records, task.Record())
+	}
+	return writeRecords(os.Stdout, records)
+}
+
+// printTask prints a single task, as an object for JSON output
+func printTask(c *config.Config, task session.GenericTask) error {
+	switch outputFormat {
+	case outputTable:
+		fmt.Println(task.PreparePretty(c.Colors))
+		return nil
+	case outputJSON:
+		return writeJSON(os.Stdout, task.Record())
+	}
+	return writeRecords(os.Stdout, []session.Record{task.Record()})
+}
+
+// writeRecords writes records in the selected machine readable format.
+// An empty list is still valid JSON, and CSV/TSV always have a header.
+func writeRecords(w io.Writer, records []session.Record) error {
+	if outputFormat == outputJSON {
+		if records == nil {
+			records = []session.Record{}
+		}
+		return writeJSON(w, records)
+	}
+	rows := make([][]string, len(records))
+	for i, r := range records {
+		rows[i] = r.Fields()
+	}
+	return writeRows(w, session.RecordHeader, rows)
+}
+
+// writeRows writes the header and rows as CSV, or TSV
+func writeRows(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if outputFormat == outputTSV {
+		cw.Comma = '\t'
+	}
+	cw.Write(header)
+	for _, row := range rows {
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	groupBy []string
+	split   string
+
+	// reportCmd represents the report command
+	reportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Report time spent by tag",
+		Long: `Sum the time spent between two dates, grouped by the values of one or more tags.
+Running tasks count up to now. Without date, today is reported. Examples:
+- traggo_cli report --period -1w --group-by project
+- traggo_cli report -s 2025-08-01 -e 2025-08-31 -g project -g type --split week
+- traggo_cli report --today -g project --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startDate, endDate, err := requiredDateRange()
+			if err != nil {
+				return err
+			}
+
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			s := session.NewTraggoSession(c)
+			report, err := s.ReportContext(cmd.Context(), startDate, endDate, groupBy, split)
+			if err != nil {
+				return err
+			}
+
+			switch outputFormat {
+			case outputJSON:
+				return writeJSON(os.Stdout, report)
+			case outputCSV, outputTSV:
+				return writeRows(os.Stdout, report.Header(), report.Rows())
+			}
+			fmt.Printf("Date range: [%s -> %s]\n", startDate.Format(time.DateTime), endDate.Format(time.DateTime))
+			fmt.Println(report.PreparePretty(c.Colors))
+			return nil
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	addDateRangeFlags(reportCmd)
+	reportCmd.Flags().StringSliceVarP(&groupBy, "group-by", "g", []string{}, "Tag names to group durations by")
+	reportCmd.Flags().StringVar(&split, "split", "", "Split the report by day, week or month")
+}
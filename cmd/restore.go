@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forceRestore  bool
+	resumeRestore string
+
+	// restoreCmd represents the restore command
+	restoreCmd = &cobra.Command{
+		Use:   "restore file",
+		Short: "Replay a backup archive into an empty account",
+		Long: `Create the tags, user settings, time spans, timers and dashboards of an archive
+written by 'traggo_cli backup' ('-' for stdin). Tasks get new ids: the mapping
+from the archive ids is printed with --output json, csv or tsv.
+The account must have no task, unless --force is given.
+A failing restore saves what was created in a file of undo.dir (configuration
+file), given to --resume to continue the restore without duplicates. Examples:
+- traggo_cli restore traggo-2025-12-01.json
+- traggo_cli restore --resume ~/.config/traggo/undo/restore-20251201-101500.json traggo-2025-12-01.json
+- gunzip -c traggo.json.gz | traggo_cli restore -o csv - > ids.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var r io.Reader = os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			archive, err := session.ReadArchive(r)
+			if err != nil {
+				return err
+			}
+
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			s := session.NewTraggoSession(c)
+			var report session.RestoreReport
+			if resumeRestore != "" {
+				previous, err := readRestoreReport(resumeRestore)
+				if err != nil {
+					return err
+				}
+				report, err = s.ResumeRestoreContext(cmd.Context(), archive, previous)
+			} else {
+				report, err = s.RestoreContext(cmd.Context(), archive, forceRestore)
+			}
+			if errors.Is(err, session.ErrNotEmpty) {
+				return fmt.Errorf("%w, use --force to restore anyway (tasks may be duplicated)", err)
+			}
+			if err != nil && (len(report.Ids) > 0 || report.Tags > 0 || report.Dashboards > 0) {
+				path, werr := writeRestoreReport(c, report)
+				if werr != nil {
+					return errors.Join(err, fmt.Errorf("cannot save the restore progress: %w", werr))
+				}
+				fmt.Fprintf(os.Stderr, "Restore interrupted, continue it with: traggo_cli restore --resume %s %s\n", path, args[0])
+			}
+			if perr := printRestoreReport(report); perr != nil && err == nil {
+				err = perr
+			}
+			return err
+		},
+	}
+)
+
+// readRestoreReport reads the progress saved by writeRestoreReport
+func readRestoreReport(path string) (session.RestoreReport, error) {
+	var report session.RestoreReport
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+	err = json.Unmarshal(d, &report)
+	if err != nil {
+		return report, fmt.Errorf("invalid restore progress %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// writeRestoreReport saves the progress of a failed restore and returns the file path
+func writeRestoreReport(c *config.Config, report session.RestoreReport) (string, error) {
+	err := os.MkdirAll(c.Undo.Dir, 0o770)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(c.Undo.Dir, fmt.Sprintf("restore-%s.json", time.Now().Format("20060102-150405")))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	err = writeJSON(f, report)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+func printRestoreReport(report session.RestoreReport) error {
+	switch outputFormat {
+	case outputJSON:
+		err := writeJSON(os.Stdout, report)
+		if err != nil {
+			return err
+		}
+	case outputCSV, outputTSV:
+		ids := make([]int, 0, len(report.Ids))
+		for id := range report.Ids {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		rows := make([][]string, len(ids))
+		for i, id := range ids {
+			rows[i] = []string{fmt.Sprint(id), fmt.Sprint(report.Ids[id])}
+		}
+		err := writeRows(os.Stdout, []string{"old", "new"}, rows)
+		if err != nil {
+			return err
+		}
+	}
+	printInfo("%d task(s), %d tag(s), %d dashboard(s) restored\n", len(report.Ids), report.Tags, report.Dashboards)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().BoolVar(&forceRestore, "force", false, "Restore even if the account already has tasks")
+	restoreCmd.Flags().StringVar(&resumeRestore, "resume", "", "Continue a failed restore from its saved progress file")
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	utils "github.com/kalidor/traggo_cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	retagFrom   string
+	retagTo     string
+	retagRegexp bool
+	retagBatch  int
+	retagYes    bool
+	retagDryRun bool
+
+	// retagCmd represents the retag command
+	retagCmd = &cobra.Command{
+		Use:   "retag",
+		Short: "Rename tags of existing tasks",
+		Long: `Rename the tags matching --from into --to, for all tasks or the ones of a date range.
+Patterns are TagName:TagValue globs: the text matched by each '*' or '?' of --from
+replaces the '*' or '?' of --to, in order. With --regexp, patterns are regular
+expressions and --to refers to groups with $1.
+New tag names must exist, see 'traggo_cli tag create'.
+Affected tasks are displayed and a confirmation is asked, unless --yes is given.
+Tasks are updated one by one, a failure does not stop the others: progress is
+reported every --batch tasks, and failures at the end.
+The whole retag is reverted with 'traggo_cli undo'. Examples:
+- traggo_cli retag --from 'ticket:OLD-*' --to 'ticket:NEW-*'
+- traggo_cli retag --from 'project:' --to 'client:' -s 2025-01-01 -e 2025-06-30
+- traggo_cli retag --regexp --from 'ticket:OLD-(\d+)' --to 'ticket:NEW-$1' --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rule, err := session.NewRetagRule(retagFrom, retagTo, retagRegexp)
+			if err != nil {
+				return err
+			}
+			startDate, endDate, err := dateRange()
+			if err != nil {
+				return err
+			}
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			s := session.NewTraggoSession(c)
+			ctx := cmd.Context()
+
+			changes, err := s.PlanRetagContext(ctx, rule, session.TaskFilter{From: startDate, To: endDate})
+			if err != nil {
+				return err
+			}
+			if len(changes) == 0 {
+				printInfo("No task to retag\n")
+				return nil
+			}
+			switch outputFormat {
+			case outputJSON:
+				err = writeJSON(os.Stdout, changes.Records())
+			case outputCSV, outputTSV:
+				err = writeRows(os.Stdout, changes.Header(), changes.Rows())
+			default:
+				fmt.Println(changes.PreparePretty(c.Colors))
+			}
+			if err != nil {
+				return err
+			}
+			if retagDryRun {
+				printInfo("Dry run, %d task(s) to retag\n", len(changes))
+				return nil
+			}
+			if !retagYes {
+				r, err := utils.AskAndCompare(fmt.Sprintf("Retag %d task(s). Confirm (y/N): ", len(changes)), "y")
+				if err != nil {
+					return err
+				}
+				if !r {
+					fmt.Println("Aborting...")
+					return nil
+				}
+			}
+
+			report, err := s.ApplyRetagContext(ctx, changes, retagBatch, func(done, total int) {
+				fmt.Fprintf(os.Stderr, "\rretagged %d/%d", done, total)
+			})
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return err
+			}
+			for _, f := range report.Failures {
+				fmt.Fprintf(os.Stderr, "task %d: %s\n", f.Change.Task.GetId(), f.Err)
+			}
+			printInfo("%d updated, %d queued, %d failure(s)\n", report.Updated, report.Queued, len(report.Failures))
+			if report.Queued > 0 {
+				handleQueued(session.ErrQueued)
+			}
+			if len(report.Failures) > 0 {
+				return errors.New("some tasks have not been retagged")
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(retagCmd)
+	addDateRangeFlags(retagCmd)
+	retagCmd.Flags().StringVar(&retagFrom, "from", "", "Tags to rename: TagName:TagValue pattern (any value if empty)")
+	retagCmd.Flags().StringVar(&retagTo, "to", "", "New tag: TagName:TagValue replacement")
+	retagCmd.Flags().BoolVar(&retagRegexp, "regexp", false, "Patterns are regular expressions instead of globs")
+	retagCmd.Flags().IntVar(&retagBatch, "batch", 50, "Number of tasks updated between two progress reports, tasks being updated one by one")
+	retagCmd.Flags().BoolVar(&retagYes, "yes", false, "Retag without confirmation")
+	retagCmd.Flags().BoolVar(&retagDryRun, "dry-run", false, "Show affected tasks without updating them")
+	retagCmd.MarkFlagRequired("from")
+	retagCmd.MarkFlagRequired("to")
+}
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	config "github.com/kalidor/traggo_cli/config"
 	session "github.com/kalidor/traggo_cli/session"
+	"github.com/kalidor/traggo_cli/tui"
 	utils "github.com/kalidor/traggo_cli/utils"
 	"github.com/spf13/cobra"
 )
@@ -18,12 +24,20 @@ var (
 
 	// rmCmd represents the rm command
 	rmCmd = &cobra.Command{
-		Use:   "rm",
+		Use:   "rm [id|id-id]...",
 		Short: "Delete task(s)",
-		Long: `Delete task(s).
-	
+		Long: `Delete task(s) selected by ids, id ranges and/or filters.
+Tasks to delete are displayed and a confirmation is asked, unless --yes is given.
+Deleted tasks are saved in an undo file (undo.dir in configuration file)
+which can be imported back with 'traggo_cli import', --start-timers starting the
+deleted timers again.
+Filters never select running timers, give their id to delete them. --all
+deletes the running timers too.
+Without selection, tasks to delete are picked among the recent ones.
+
 - ./traggo_cli rm -i 222,223,224
 - ./traggo_cli rm 222-300
+- ./traggo_cli rm --filter-tag project:old --from 2025-01-01 --to 2025-03-31
 - ./traggo_cli rm --all # will ask confirmation
 - ./traggo_cli rm --all --yes # will NOT ask confirmation
 `,
@@ -32,53 +46,171 @@ var (
 )
 
 func runRmE(cmd *cobra.Command, args []string) error {
-	c := config.LoadConfig(configPath)
-	s := session.NewTraggoSession(c)
-	s.ListCurrentTasks()
-	if rmAll {
-		if rmAllYes {
-			fmt.Println("TODO: will remove all without confirmation")
-			// s.DeleteAll() // TODO: to implement
-		} else {
-			r, err := utils.AskAndCompare("Delete all. Confirm (\"Yes, I'm sure\"/N): ", "Yes, I'm sure")
-			if err != nil {
-				return err
-			}
-			if r {
-				fmt.Println("TODO: will remove all after confirmation")
-				// s.DeleteAll()
-			} else {
-				fmt.Println("Aborting...")
-			}
+	selected := slices.Clone(ids)
+	if rangeIds != "" {
+		args = append(args, rangeIds)
+	}
+	for _, arg := range args {
+		argIds, err := parseIdArg(arg)
+		if err != nil {
+			return err
 		}
-		return nil
+		selected = append(selected, argIds...)
 	}
-	if len(ids) > 0 {
-		s.Delete(ids)
-		return nil
+	slices.Sort(selected)
+	selected = slices.Compact(selected)
+
+	if rmAll && (len(selected) > 0 || hasFilter()) {
+		return errors.New("--all cannot be used with ids or filters")
+	}
+	filter, err := buildFilter()
+	if err != nil {
+		return err
 	}
 
-	if strings.Contains(rangeIds, "-") {
-		ids, err := handleRangeIds(rangeIds)
+	c, err := config.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	s := session.NewTraggoSession(c)
+	ctx := cmd.Context()
+
+	if !rmAll && len(selected) == 0 && !hasFilter() {
+		picked, err := tui.PickTasks(ctx, s, filter, true)
 		if err != nil {
 			return err
 		}
-		s.Delete(ids)
+		if len(picked) == 0 {
+			fmt.Println("Aborting...")
+			return nil
+		}
+		for _, task := range picked {
+			selected = append(selected, task.GetId())
+		}
+	}
+	filter.Ids = selected
+
+	tasks, err := s.SearchTasksContext(ctx, filter)
+	if errors.Is(err, session.ErrUnreachable) && !hasFilter() && len(selected) > 0 {
+		// deletions by id can be queued, but nothing can be saved for undo
+		printInfo("Traggo is unreachable, tasks cannot be displayed nor saved for undo\n")
+		ok, err := confirmRm(fmt.Sprintf("Delete %d task(s)", len(selected)), "y")
+		if err != nil || !ok {
+			return err
+		}
+		return handleQueued(s.DeleteContext(ctx, selected))
+	}
+	if err != nil {
+		return err
+	}
+	timers, spans := session.SplitTasks(tasks)
+	if len(selected) == 0 && !rmAll {
+		// filters only select done tasks
+		timers = session.TimersData{}
+	}
+	if timers.IsEmpty() && spans.IsEmpty() {
+		printInfo("No task to delete\n")
 		return nil
+	}
+	for _, id := range selected {
+		if !slices.ContainsFunc(tasks, func(task session.GenericTask) bool { return task.GetId() == id }) {
+			printInfo("task %d not found\n", id)
+		}
+	}
+
+	err = printTasks(c, timers, spans)
+	if err != nil {
+		return err
+	}
+	count := len(timers.Timers) + len(spans)
+	prompt, expected := fmt.Sprintf("Delete %d task(s)", count), "y"
+	if rmAll {
+		prompt, expected = fmt.Sprintf("Delete all %d task(s)", count), "Yes, I'm sure"
+	}
+	ok, err := confirmRm(prompt, expected)
+	if err != nil || !ok {
+		return err
+	}
 
+	undoFile, err := writeUndoFile(c, timers, spans)
+	if err != nil {
+		return fmt.Errorf("cannot save tasks before deletion: %w", err)
+	}
+	deleted := make([]session.GenericTask, 0, count)
+	for _, task := range timers.Timers {
+		deleted = append(deleted, task)
 	}
+	for _, task := range spans {
+		deleted = append(deleted, task)
+	}
+	err = handleQueued(s.DeleteTasksContext(ctx, deleted))
+	if err != nil {
+		return err
+	}
+	restore := "traggo_cli import"
+	if !timers.IsEmpty() {
+		restore += " --start-timers"
+	}
+	printInfo("%d task(s) deleted, recreate them with: %s %s\n", count, restore, undoFile)
 	return nil
 }
 
+// confirmRm asks to type expected, unless --yes is given
+func confirmRm(prompt, expected string) (bool, error) {
+	if rmAllYes {
+		return true, nil
+	}
+	ok, err := utils.AskAndCompare(fmt.Sprintf("%s. Confirm (\"%s\"/N): ", prompt, expected), expected)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		fmt.Println("Aborting...")
+	}
+	return ok, nil
+}
+
+// writeUndoFile saves tasks in the import format and returns the file path
+func writeUndoFile(c *config.Config, timers session.TimersData, spans session.TimeSpanTaskList) (string, error) {
+	err := os.MkdirAll(c.Undo.Dir, 0o770)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(c.Undo.Dir, fmt.Sprintf("rm-%s.json", time.Now().Format("20060102-150405")))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	err = writeJSON(f, append(timers.Records(), spans.Records()...))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
 func init() {
 	rootCmd.AddCommand(rmCmd)
+	addFilterFlags(rmCmd)
 	rmCmd.Flags().IntSliceVarP(&ids, "ids", "i", []int{}, "List of id to delete")
 	rmCmd.Flags().StringVarP(&rangeIds, "range", "r", "", "IDs range to delete (1-12)")
 	rmCmd.Flags().BoolVar(&rmAll, "all", false, "Remove all tasks. Will ask confirmation.")
-	rmCmd.Flags().BoolVar(&rmAllYes, "yes", false, "Remove all tasks without confirmation /!\\")
-
-	rmCmd.MarkFlagsOneRequired("ids", "range")
+	rmCmd.Flags().BoolVar(&rmAllYes, "yes", false, "Remove tasks without confirmation /!\\")
+}
 
+// parseIdArg converts an id or a range of ids (12-20)
+func parseIdArg(arg string) ([]int, error) {
+	if strings.Contains(arg, "-") {
+		return handleRangeIds(arg)
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(arg))
+	if err != nil {
+		return nil, fmt.Errorf("invalid task id '%s'", arg)
+	}
+	return []int{id}, nil
 }
 
 func handleRangeIds(arg string) ([]int, error) {
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
@@ -15,14 +17,14 @@ var (
 	verbose    bool
 	// rootCmd represents the base command when called without any subcommands
 	rootCmd = &cobra.Command{
-		Use:              "traggo_cli",
-		Short:            "Traggo CLI to interact with Traggo using API only.",
-		PersistentPreRun: preRunRoot,
+		Use:               "traggo_cli",
+		Short:             "Traggo CLI to interact with Traggo using API only.",
+		PersistentPreRunE: preRunRoot,
 	}
 	printBody func(r http.Response)
 )
 
-func preRunRoot(cmd *cobra.Command, args []string) {
+func preRunRoot(cmd *cobra.Command, args []string) error {
 	if verbose {
 		fmt.Printf("verbose=%t\n", verbose)
 		printBody = func(r http.Response) {
@@ -30,10 +32,14 @@ func preRunRoot(cmd *cobra.Command, args []string) {
 			fmt.Println(string(b))
 		}
 	}
+	return checkOutputFormat()
 }
 
 func Execute() {
-	err := rootCmd.Execute()
+	// requests in progress are cancelled on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
 	if err != nil {
 		os.Exit(1)
 	}
@@ -49,4 +55,5 @@ func init() {
 	rootCmd.Flags().BoolP("help", "h", false, "Help message")
 	rootCmd.Flags().StringVarP(&configPath, "config", "c", defaultConfigPath, "Full path of config file")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Print body response")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format: json, csv, tsv or table")
 }
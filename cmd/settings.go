@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	config "github.com/kalidor/traggo_cli/config"
 	session "github.com/kalidor/traggo_cli/session"
 	"github.com/spf13/cobra"
@@ -10,10 +12,18 @@ import (
 var settingsCmd = &cobra.Command{
 	Use:   "settings",
 	Short: "Retrieve userSettings",
-	Run: func(cmd *cobra.Command, args []string) {
-		c := config.LoadConfig(configPath)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := config.LoadConfig(configPath)
+		if err != nil {
+			return err
+		}
 		s := session.NewTraggoSession(c)
-		s.GetSettings()
+		settings, err := s.GetSettingsContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		fmt.Println(settings)
+		return nil
 	},
 }
 
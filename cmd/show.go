@@ -1,13 +1,12 @@
 package cmd
 
 import (
-	"errors"
-	"fmt"
 	"strconv"
 	"strings"
 
 	config "github.com/kalidor/traggo_cli/config"
 	session "github.com/kalidor/traggo_cli/session"
+	"github.com/kalidor/traggo_cli/tui"
 	"github.com/spf13/cobra"
 )
 
@@ -15,30 +14,57 @@ import (
 var showCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show details for specific id",
+	Long: `Show details of tasks selected by id and/or filters. Examples:
+- traggo_cli show 12 13
+- traggo_cli show --filter-tag project:traggo
+- traggo_cli show --filter-note meeting --from 2025-08-01 --to 2025-08-31
+Without id nor filter, tasks to show are picked among the recent ones.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := buildFilter()
+		if err != nil {
+			return err
+		}
+		c, err := config.LoadConfig(configPath)
+		if err != nil {
+			return err
+		}
+		s := session.NewTraggoSession(c)
+		ctx := cmd.Context()
+
+		if len(args) == 0 && !hasFilter() {
+			picked, err := tui.PickTasks(ctx, s, filter, true)
+			if err != nil {
+				return err
+			}
+			return printTaskList(c, picked)
+		}
 		if len(args) == 0 {
-			return errors.New("this command requiers at least one task id")
+			res, err := s.SearchTasksContext(ctx, filter)
+			if err != nil {
+				return err
+			}
+			return printTaskList(c, res)
 		}
 
-		c := config.LoadConfig(configPath)
-		s := session.NewTraggoSession(c)
-		var res session.GenericTask
+		var found []session.GenericTask
 		for _, idStr := range args {
 			idStr = strings.TrimSpace(idStr)
 			id, err := strconv.Atoi(idStr)
 			if err != nil {
 				continue
 			}
-			res = s.SearchTask(id)
-			if res == nil {
-				continue
+			filter.Id = id
+			res, err := s.SearchTasksContext(ctx, filter)
+			if err != nil {
+				return err
 			}
-			fmt.Println(res.PreparePretty(c.Colors))
+			found = append(found, res...)
 		}
-		return nil
+		return printTaskList(c, found)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(showCmd)
+	addFilterFlags(showCmd)
 }
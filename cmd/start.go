@@ -1,14 +1,20 @@
 package cmd
 
 import (
+	"errors"
+	"time"
+
 	config "github.com/kalidor/traggo_cli/config"
 	session "github.com/kalidor/traggo_cli/session"
+	utils "github.com/kalidor/traggo_cli/utils"
 	"github.com/spf13/cobra"
 )
 
 var (
 	tags []string
 	// note string // Already defined
+	atStr  string
+	agoStr string
 
 	// startCmd represents the start command
 	startCmd = &cobra.Command{
@@ -17,19 +23,68 @@ var (
 		Long: `Start a task with tags:
 	
 - traggo_cli start [-t | --tags key1:value1] [-t | --tags key2:value2]
-- traggo_cli start -t tag:key -n "Test if this is possible to do"`,
-		Run: func(cmd *cobra.Command, args []string) {
-			c := config.LoadConfig(configPath)
+- traggo_cli start -t tag:key -n "Test if this is possible to do"
+- traggo_cli start -t tag:key --ago 15m
+- traggo_cli start -t tag:key --at 9:30`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			at, err := parseAt()
+			if err != nil {
+				return err
+			}
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
 			s := session.NewTraggoSession(c)
-			s.Start(tags, note)
+			ctx := cmd.Context()
+			if !at.IsZero() {
+				// the new timer covers the time since at
+				err = checkOverlap(ctx, s, session.TimeSpanTask{TimerTask: session.TimerTask{Start: at}})
+				if err != nil {
+					return err
+				}
+			}
+			task, err := s.StartAtContext(ctx, tags, note, at)
+			if err != nil && !errors.Is(err, session.ErrQueued) {
+				return err
+			}
+			pErr := printTask(c, task)
+			if pErr != nil {
+				return pErr
+			}
+			return handleQueued(err)
 		},
 	}
 )
 
+// parseAt returns the time given by --at or --ago, zero for now
+func parseAt() (time.Time, error) {
+	switch {
+	case atStr != "":
+		return utils.ParseDate(atStr, time.Now())
+	case agoStr != "":
+		d, err := utils.ParseDuration(agoStr)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, nil
+}
+
+// addAtFlags adds --at and --ago to cmd, read by parseAt
+func addAtFlags(cmd *cobra.Command, action string) {
+	cmd.Flags().StringVar(&atStr, "at", "", action+" at this date instead of now: "+utils.DateHelp)
+	cmd.Flags().StringVar(&agoStr, "ago", "", action+" this long ago instead of now (15m, 1h30m...)")
+	cmd.MarkFlagsMutuallyExclusive("at", "ago")
+}
+
 func init() {
 	rootCmd.AddCommand(startCmd)
 	startCmd.Flags().StringArrayVarP(&tags, "tags", "t", []string{}, "List of tags")
 	startCmd.Flags().StringVarP(&note, "note", "n", "", "Note associated to this task")
+	startCmd.Flags().BoolVar(&allowOverlap, "overlap", false, "Allow starting in the past over existing tasks")
+	addAtFlags(startCmd, "Start")
 	startCmd.MarkFlagRequired("tags")
 
 }
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsTags   []string
+	excludeTags []string
+	requireTags []string
+
+	// statsCmd represents the stats command
+	statsCmd = &cobra.Command{
+		Use:   "stats",
+		Short: "Time spent by tag, computed by Traggo",
+		Long: `Time spent by tag value, computed by Traggo like its dashboards do.
+Unlike 'report', time spans are not downloaded. Without date, today is used. Examples:
+- traggo_cli stats --tag project --period -1m
+- traggo_cli stats --tag project --exclude-tag type:meeting -s 2025-08-01 -e 2025-08-31 --split week
+- traggo_cli stats --tag type --require-tag project:cli --today --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(statsTags) == 0 {
+				return errors.New("at least one --tag is required")
+			}
+			exclude, err := parseTagFilters(excludeTags)
+			if err != nil {
+				return err
+			}
+			require, err := parseTagFilters(requireTags)
+			if err != nil {
+				return err
+			}
+			startDate, endDate, err := requiredDateRange()
+			if err != nil {
+				return err
+			}
+			ranges, err := session.StatsRanges(startDate, endDate, split)
+			if err != nil {
+				return err
+			}
+
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			s := session.NewTraggoSession(c)
+			stats, err := s.StatsContext(cmd.Context(), session.StatsQuery{
+				Ranges:      ranges,
+				Tags:        statsTags,
+				ExcludeTags: exclude,
+				RequireTags: require,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch outputFormat {
+			case outputJSON:
+				if stats == nil {
+					stats = session.StatsList{}
+				}
+				return writeJSON(os.Stdout, stats)
+			case outputCSV, outputTSV:
+				return writeRows(os.Stdout, stats.Header(), stats.Rows())
+			}
+			fmt.Printf("Date range: [%s -> %s]\n", startDate.Format(time.DateTime), endDate.Format(time.DateTime))
+			fmt.Println(stats.PreparePretty(c.Colors))
+			return nil
+		},
+	}
+)
+
+// parseTagFilters converts TagName:TagValue strings to tags
+func parseTagFilters(filters []string) ([]session.Tag, error) {
+	var tags []session.Tag
+	for _, f := range filters {
+		key, value, ok := strings.Cut(f, ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid tag '%s', expected TagName:TagValue", f)
+		}
+		tags = append(tags, session.Tag{Key: key, Value: value})
+	}
+	return tags, nil
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	addDateRangeFlags(statsCmd)
+	statsCmd.Flags().StringSliceVar(&statsTags, "tag", []string{}, "Tag names to sum up by value")
+	statsCmd.Flags().StringSliceVar(&excludeTags, "exclude-tag", []string{}, "Ignore time spans with this tag (TagName:TagValue)")
+	statsCmd.Flags().StringSliceVar(&requireTags, "require-tag", []string{}, "Only count time spans with this tag (TagName:TagValue)")
+	statsCmd.Flags().StringVar(&split, "split", "", "Split the range by day, week or month")
+}
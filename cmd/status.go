@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusTemplate string
+	statusIdle     string
+	statusWaybar   bool
+
+	// statusCmd represents the status command
+	statusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Print the running timer on one line, for prompts and status bars",
+		Long: `Print the latest started timer through a text/template, for tmux, starship,
+i3blocks or waybar. Fields of the template:
+  .Tags      tags, key:value space separated     .Values  values of the tags
+  .Note      note                                .Id      id of the timer
+  .Start     start, a time.Time                  .Elapsed running duration, 1h05m
+  .Duration  running duration, a time.Duration   .Count   number of running timers
+  .Timers    all the running timers                .Stale   Traggo failed, last known timers
+Nothing, or --idle, is printed without running timer, and '?' if Traggo fails
+without known timers.
+
+Timers are read from a local cache refreshed every few seconds (status.ttl in
+the configuration file), so Traggo is not requested on every prompt. Commands
+changing a timer refresh it immediately. The refresh is a single request of at
+most status.timeout (2s by default), the last known timers being used if it fails.
+
+- traggo_cli status
+- traggo_cli status --template '{{.Values}} ({{.Elapsed}}){{if gt .Count 1}} +{{.Count}}{{end}}'
+- traggo_cli status --waybar`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			tmpl, idle := statusTemplate, statusIdle
+			if !cmd.Flags().Changed("template") && c.Status.Template != "" {
+				tmpl = c.Status.Template
+			}
+			if !cmd.Flags().Changed("idle") && c.Status.Idle != "" {
+				idle = c.Status.Idle
+			}
+			s := session.NewTraggoSession(c)
+
+			timers, stale, err := s.CurrentTimersContext(cmd.Context())
+			if err != nil {
+				if statusWaybar {
+					// waybar expects JSON whatever happens
+					return writeWaybar(waybarStatus{Text: "?", Tooltip: err.Error(), Class: "error"})
+				}
+				// a prompt shows a short mark rather than a failing command
+				fmt.Println("?")
+				fmt.Fprintln(os.Stderr, err)
+				return nil
+			}
+			status := session.NewStatus(timers)
+			status.Stale = stale
+			text := idle
+			if status.Running {
+				text, err = status.Render(tmpl)
+				if err != nil {
+					return err
+				}
+			}
+			switch {
+			case statusWaybar:
+				w := waybarStatus{Text: text, Class: "idle", Alt: "idle"}
+				if status.Running {
+					w.Class, w.Alt = "running", "running"
+					w.Tooltip = statusTooltip(status)
+				}
+				if status.Stale {
+					w.Class = "stale"
+					w.Tooltip = strings.TrimSpace(w.Tooltip + "\nTraggo failed, last known timers")
+				}
+				return writeWaybar(w)
+			case outputFormat == outputJSON:
+				return writeJSON(os.Stdout, status)
+			default:
+				fmt.Println(text)
+			}
+			return nil
+		},
+	}
+)
+
+// waybarStatus is the output of a waybar custom module with "return-type": "json"
+type waybarStatus struct {
+	Text    string `json:"text"`
+	Alt     string `json:"alt,omitempty"`
+	Tooltip string `json:"tooltip,omitempty"`
+	Class   string `json:"class,omitempty"`
+}
+
+// writeWaybar writes w on a single line, as waybar reads one status per line
+func writeWaybar(w waybarStatus) error {
+	d, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(d))
+	return nil
+}
+
+// statusTooltip describes all the running timers, one per line
+func statusTooltip(status session.Status) string {
+	lines := make([]string, len(status.Timers))
+	for i, timer := range status.Timers {
+		elapsed := session.FormatElapsed(max(0, session.TimeNow().Sub(timer.Start)))
+		lines[i] = fmt.Sprintf("%s since %s (%s)", strings.Join(timer.ExportTags(), " "), timer.Start.Local().Format("15:04"), elapsed)
+		if timer.Note != "" {
+			lines[i] += ": " + timer.Note
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringVar(&statusTemplate, "template", config.DefaultStatusTemplate, "text/template of the status, see the fields above")
+	statusCmd.Flags().StringVar(&statusIdle, "idle", "", "Printed without running timer")
+	statusCmd.Flags().BoolVar(&statusWaybar, "waybar", false, "Print JSON for a waybar custom module (return-type json)")
+}
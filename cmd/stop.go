@@ -1,29 +1,176 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
 	config "github.com/kalidor/traggo_cli/config"
 	session "github.com/kalidor/traggo_cli/session"
+	"github.com/kalidor/traggo_cli/tui"
+	utils "github.com/kalidor/traggo_cli/utils"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
-	ids []int
+	ids      []int
+	stopLast bool
+	stopAll  bool
 )
 
 // stopCmd represents the stop command
 var stopCmd = &cobra.Command{
-	Use:   "stop",
-	Short: "Stop given IDs",
-	Run: func(cmd *cobra.Command, args []string) {
-		c := config.LoadConfig(configPath)
+	Use:   "stop [id | TagName:TagValue]...",
+	Short: "Stop running timers",
+	Long: `Stop running timers, now or at a date in the past. Timers are selected by id,
+by tag (any value if TagValue is empty), the last started one or all of them.
+Without selection, the only running timer is stopped, or the timers to stop are picked:
+
+- traggo_cli stop project:foo
+- traggo_cli stop 12 13 --ago 10m
+- traggo_cli stop --last --at "yesterday 18:30"
+- traggo_cli stop --all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		at, err := parseAt()
+		if err != nil {
+			return err
+		}
+		c, err := config.LoadConfig(configPath)
+		if err != nil {
+			return err
+		}
 		s := session.NewTraggoSession(c)
-		s.Stop(c.Colors, ids)
+		ctx := cmd.Context()
+		// ids alone are stopped without looking for them, which can be queued
+		selected := ids
+		if len(ids) == 0 || len(args) > 0 || stopLast || stopAll {
+			for _, id := range ids {
+				args = append(args, strconv.Itoa(id))
+			}
+			selected, err = selectTimers(ctx, c, s, args, stopLast, stopAll)
+			if err != nil {
+				return err
+			}
+		}
+		if len(selected) == 0 {
+			fmt.Println("Aborting...")
+			return nil
+		}
+		stopped, err := s.StopAtContext(ctx, selected, at)
+		if err != nil && !errors.Is(err, session.ErrQueued) {
+			return err
+		}
+		pErr := printTasks(c, session.TimersData{}, stopped)
+		if pErr != nil {
+			return pErr
+		}
+		return handleQueued(err)
 	},
 }
 
+// selectTimers returns the ids of the running timers selected by args, the
+// last started one and all of them, or asked to the user without selection:
+// picked in a terminal, typed otherwise
+func selectTimers(ctx context.Context, c *config.Config, s *session.Traggo, args []string, last, all bool) ([]int, error) {
+	timers, err := s.ListCurrentTasksContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if timers.IsEmpty() {
+		return nil, errors.New("no running timer")
+	}
+
+	var selected []int
+	add := func(timer session.TimerTask) {
+		for _, id := range selected {
+			if id == timer.Id {
+				return
+			}
+		}
+		selected = append(selected, timer.Id)
+	}
+	for _, arg := range args {
+		filter := session.TaskFilter{}
+		key, value, isTag := strings.Cut(arg, ":")
+		if isTag {
+			filter.TagKey, filter.TagValue = key, value
+		} else {
+			filter.Id, err = strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timer id or TagName:TagValue '%s'", arg)
+			}
+		}
+		found := timers.Filter(filter)
+		if found.IsEmpty() {
+			return nil, fmt.Errorf("no running timer matches '%s'", arg)
+		}
+		for _, timer := range found.Timers {
+			add(timer)
+		}
+	}
+	if last {
+		timer, _ := timers.Last()
+		add(timer)
+	}
+	if all {
+		for _, timer := range timers.Timers {
+			add(timer)
+		}
+	}
+	if len(selected) > 0 || len(args) > 0 || last || all {
+		return selected, nil
+	}
+
+	if len(timers.Timers) == 1 {
+		return []int{timers.Timers[0].Id}, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return askTimers(c, timers)
+	}
+	running := make([]session.GenericTask, len(timers.Timers))
+	for i, timer := range timers.Timers {
+		running[i] = timer
+	}
+	picked, err := tui.Pick(running, true)
+	for _, task := range picked {
+		selected = append(selected, task.GetId())
+	}
+	return selected, err
+}
+
+// askTimers shows the running timers and asks the ones to stop
+func askTimers(c *config.Config, timers session.TimersData) ([]int, error) {
+	fmt.Println(timers.PreparePretty(c.Colors))
+	answer, err := utils.Ask("Timers to stop (ids separated by spaces or commas, 'all', nothing to abort):")
+	if err != nil {
+		return nil, err
+	}
+	if answer == "all" {
+		var all []int
+		for _, timer := range timers.Timers {
+			all = append(all, timer.Id)
+		}
+		return all, nil
+	}
+	var selected []int
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timer id '%s'", field)
+		}
+		selected = append(selected, id)
+	}
+	return selected, nil
+}
+
 func init() {
 	rootCmd.AddCommand(stopCmd)
 	stopCmd.Flags().IntSliceVarP(&ids, "ids", "i", []int{}, "List of id to stop")
-	stopCmd.MarkFlagRequired("ids")
-
+	stopCmd.Flags().BoolVarP(&stopLast, "last", "l", false, "Stop the last started timer")
+	stopCmd.Flags().BoolVarP(&stopAll, "all", "a", false, "Stop all running timers")
+	addAtFlags(stopCmd, "Stop")
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// tags []string // already declared
+	// note string   // already declared
+	switchLast bool
+
+	// switchCmd represents the switch command
+	switchCmd = &cobra.Command{
+		Use:   "switch [id | TagName:TagValue]...",
+		Short: "Stop running timers and start a new one",
+		Long: `Stop running timers and start a new task at the very same time, without gap nor
+overlap. All running timers are stopped, unless some are selected by id, by tag
+or with --last. If the new task cannot be started, the stopped timers run again:
+
+- traggo_cli switch -t project:bar -n "Review"
+- traggo_cli switch project:foo -t project:bar
+- traggo_cli switch --last -t project:bar`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			s := session.NewTraggoSession(c)
+			ctx := cmd.Context()
+			var selected []int
+			if len(args) > 0 || switchLast {
+				selected, err = selectTimers(ctx, c, s, args, switchLast, false)
+				if err != nil {
+					return err
+				}
+			} else {
+				timers, err := s.ListCurrentTasksContext(ctx)
+				if err != nil {
+					return err
+				}
+				for _, timer := range timers.Timers {
+					selected = append(selected, timer.Id)
+				}
+			}
+			stopped, started, err := s.SwitchContext(ctx, selected, tags, note)
+			if err != nil && !errors.Is(err, session.ErrQueued) {
+				return err
+			}
+			pErr := printTasks(c, session.TimersData{Timers: []session.TimerTask{started}}, stopped)
+			if pErr != nil {
+				return pErr
+			}
+			return handleQueued(err)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+	switchCmd.Flags().StringArrayVarP(&tags, "tags", "t", []string{}, "List of tags of the new task")
+	switchCmd.Flags().StringVarP(&note, "note", "n", "", "Note associated to the new task")
+	switchCmd.Flags().BoolVarP(&switchLast, "last", "l", false, "Only stop the last started timer")
+	switchCmd.MarkFlagRequired("tags")
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dropConflicts bool
+	listQueue     bool
+
+	// syncCmd represents the sync command
+	syncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Send operations queued while Traggo was unreachable",
+		Long: `Send operations queued while Traggo was unreachable, in the order they were done.
+Operations rejected by the server are reported as conflicts and kept in the queue.
+Operations failing once sent, on a timeout for example, may have been applied by
+Traggo: they are reported to be checked, and removed from the queue so they are
+never applied twice.
+
+- traggo_cli sync
+- traggo_cli sync --list # show queued operations without sending them
+- traggo_cli sync --drop-conflicts # forget operations rejected by the server`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			s := session.NewTraggoSession(c)
+			if s.Queue == nil {
+				return errors.New("no offline queue configured")
+			}
+			if listQueue {
+				ops, err := s.Queue.Load()
+				if err != nil {
+					return err
+				}
+				if len(ops) == 0 {
+					fmt.Println("Queue is empty")
+				}
+				for _, op := range ops {
+					fmt.Println(op)
+				}
+				return nil
+			}
+
+			report, err := s.SyncContext(cmd.Context(), dropConflicts)
+			for _, op := range report.Sent {
+				fmt.Printf("sent: %s\n", op)
+			}
+			for _, conflict := range report.Conflicts {
+				fmt.Printf("conflict: %s: %s\n", conflict.Operation, conflict.Err)
+			}
+			for _, uncertain := range report.Uncertain {
+				fmt.Printf("maybe applied: %s: %s\n", uncertain.Operation, uncertain.Err)
+			}
+			if len(report.Pending) > 0 {
+				fmt.Printf("Traggo is still unreachable, %d operation(s) pending\n", len(report.Pending))
+			}
+			if err != nil {
+				return err
+			}
+			if len(report.Conflicts) > 0 && !dropConflicts {
+				return fmt.Errorf("%d conflict(s) kept in queue, use --drop-conflicts to forget them", len(report.Conflicts))
+			}
+			if len(report.Uncertain) > 0 {
+				return fmt.Errorf("%d operation(s) may have been applied, check them in Traggo", len(report.Uncertain))
+			}
+			return nil
+		},
+	}
+)
+
+// handleQueued tells the user an operation has been queued. Other errors are returned untouched.
+func handleQueued(err error) error {
+	if errors.Is(err, session.ErrQueued) {
+		fmt.Fprintln(os.Stderr, "Traggo is unreachable: operation queued, run 'traggo_cli sync' once back online")
+		return nil
+	}
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().BoolVar(&dropConflicts, "drop-conflicts", false, "Remove operations rejected by the server from the queue")
+	syncCmd.Flags().BoolVarP(&listQueue, "list", "l", false, "List queued operations without sending them")
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	utils "github.com/kalidor/traggo_cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagColor  string
+	tagNewKey string
+	tagYes    bool
+
+	// tagCmd represents the tag command
+	tagCmd = &cobra.Command{
+		Use:   "tag",
+		Short: "Manage tag names",
+		Long: `List, create, rename, recolor and delete the tag names known by Traggo.
+
+- traggo_cli tag list
+- traggo_cli tag create project --color '#2196f3'
+- traggo_cli tag update project --key client --color '#ff9800'
+- traggo_cli tag delete client`,
+	}
+
+	tagListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List tags with their usage count",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, s, err := tagSession()
+			if err != nil {
+				return err
+			}
+			tags, err := s.GetTagsContext(cmd.Context())
+			if err != nil {
+				return err
+			}
+			switch outputFormat {
+			case outputJSON:
+				if tags == nil {
+					tags = session.TagDefinitions{}
+				}
+				return writeJSON(os.Stdout, tags)
+			case outputCSV, outputTSV:
+				return writeRows(os.Stdout, tags.Header(), tags.Rows())
+			}
+			fmt.Println(tags.PreparePretty(c.Colors))
+			return nil
+		},
+	}
+
+	tagCreateCmd = &cobra.Command{
+		Use:   "create TagName...",
+		Short: "Create tags",
+		Long:  `Create tags. Without --color, a color is chosen from the tag name.`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if tagColor != "" {
+				err := session.CheckColor(tagColor)
+				if err != nil {
+					return err
+				}
+			}
+			_, s, err := tagSession()
+			if err != nil {
+				return err
+			}
+			for _, key := range args {
+				color := tagColor
+				if color == "" {
+					color = session.TagColor(key)
+				}
+				tag, err := s.CreateTagContext(cmd.Context(), key, color)
+				if err != nil {
+					return fmt.Errorf("tag '%s': %w", key, err)
+				}
+				printInfo("Tag '%s' created (%s)\n", tag.Key, tag.Color)
+			}
+			return nil
+		},
+	}
+
+	tagUpdateCmd = &cobra.Command{
+		Use:   "update TagName",
+		Short: "Rename or recolor a tag",
+		Long: `Rename a tag with --key and/or change its color with --color.
+Time spans using the tag are renamed too.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if tagNewKey == "" && tagColor == "" {
+				return fmt.Errorf("nothing to update, provide --key and/or --color")
+			}
+			if tagColor != "" {
+				err := session.CheckColor(tagColor)
+				if err != nil {
+					return err
+				}
+			}
+			_, s, err := tagSession()
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			tags, err := s.GetTagsContext(ctx)
+			if err != nil {
+				return err
+			}
+			tag, ok := tags.Get(args[0])
+			if !ok {
+				return fmt.Errorf("unknown tag '%s'", args[0])
+			}
+			if tagNewKey != "" && tagNewKey != tag.Key && tags.Contain(tagNewKey) {
+				return fmt.Errorf("tag '%s' already exists", tagNewKey)
+			}
+			color := tag.Color
+			if tagColor != "" {
+				color = tagColor
+			}
+			updated, err := s.UpdateTagContext(ctx, tag.Key, tagNewKey, color)
+			if err != nil {
+				return err
+			}
+			printInfo("Tag '%s' updated: '%s' (%s)\n", tag.Key, updated.Key, updated.Color)
+			return nil
+		},
+	}
+
+	tagDeleteCmd = &cobra.Command{
+		Use:   "delete TagName",
+		Short: "Delete a tag",
+		Long: `Delete a tag. Its usage count is displayed and a confirmation is asked,
+unless --yes is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, s, err := tagSession()
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			tags, err := s.GetTagsContext(ctx)
+			if err != nil {
+				return err
+			}
+			tag, ok := tags.Get(args[0])
+			if !ok {
+				return fmt.Errorf("unknown tag '%s'", args[0])
+			}
+			if !tagYes {
+				r, err := utils.AskAndCompare(fmt.Sprintf("Tag '%s' is used %d time(s). Delete it (y/N): ", tag.Key, tag.Usages), "y")
+				if err != nil {
+					return err
+				}
+				if !r {
+					fmt.Println("Aborting...")
+					return nil
+				}
+			}
+			err = s.RemoveTagContext(ctx, tag.Key)
+			if err != nil {
+				return err
+			}
+			printInfo("Tag '%s' deleted\n", tag.Key)
+			return nil
+		},
+	}
+)
+
+func tagSession() (*config.Config, *session.Traggo, error) {
+	c, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, session.NewTraggoSession(c), nil
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagListCmd, tagCreateCmd, tagUpdateCmd, tagDeleteCmd)
+	tagCreateCmd.Flags().StringVar(&tagColor, "color", "", "Color of the tags (#rrggbb)")
+	tagUpdateCmd.Flags().StringVar(&tagColor, "color", "", "New color of the tag (#rrggbb)")
+	tagUpdateCmd.Flags().StringVar(&tagNewKey, "key", "", "New name of the tag")
+	tagDeleteCmd.Flags().BoolVar(&tagYes, "yes", false, "Delete without confirmation")
+}
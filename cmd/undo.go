@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listJournal bool
+
+	// undoCmd represents the undo command
+	undoCmd = &cobra.Command{
+		Use:   "undo [n]",
+		Short: "Revert the last operation(s)",
+		Long: `Revert the last n operations (1 by default) done by traggo_cli: deleted tasks are
+recreated (with a new id), updated and stopped tasks get their previous fields back,
+started or created tasks are deleted. The journal keeps the last undo.size operations.
+
+- traggo_cli undo
+- traggo_cli undo 3
+- traggo_cli undo --list # show the journal, newest first`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReplay(cmd, args, true)
+		},
+	}
+
+	// redoCmd represents the redo command
+	redoCmd = &cobra.Command{
+		Use:   "redo [n]",
+		Short: "Replay the last undone operation(s)",
+		Long: `Replay the last n operations (1 by default) reverted by 'traggo_cli undo'.
+Undone operations cannot be replayed anymore once a new operation is done.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReplay(cmd, args, false)
+		},
+	}
+)
+
+func runReplay(cmd *cobra.Command, args []string, undo bool) error {
+	n := 1
+	if len(args) > 0 {
+		var err error
+		n, err = strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid number of operations '%s'", args[0])
+		}
+	}
+	c, err := config.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	s := session.NewTraggoSession(c)
+	if s.Journal == nil {
+		return errors.New("journal is disabled in configuration file")
+	}
+	if undo && listJournal {
+		entries := s.Journal.Entries()
+		if len(entries) == 0 {
+			fmt.Println("Journal is empty")
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			state := ""
+			if entries[i].Undone {
+				state = " (undone)"
+			}
+			fmt.Printf("%s %s%s\n", entries[i].Time.Local().Format(time.DateTime), entries[i], state)
+		}
+		return nil
+	}
+
+	var entries []session.JournalEntry
+	action := "undone"
+	if undo {
+		entries, err = s.UndoContext(cmd.Context(), n)
+	} else {
+		action = "redone"
+		entries, err = s.RedoContext(cmd.Context(), n)
+	}
+	for _, e := range entries {
+		fmt.Printf("%s: %s\n", action, e)
+	}
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+	undoCmd.Flags().BoolVar(&listJournal, "list", false, "Show the journal of operations without reverting anything")
+}
@@ -3,12 +3,12 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
 	config "github.com/kalidor/traggo_cli/config"
 	session "github.com/kalidor/traggo_cli/session"
+	"github.com/kalidor/traggo_cli/tui"
 	"github.com/kalidor/traggo_cli/utils"
 	"github.com/spf13/cobra"
 )
@@ -25,20 +25,23 @@ var (
 	updateCmd = &cobra.Command{
 		Use:   "update",
 		Short: "Update field(s) from specific task ID",
-		Long: `Update one or more fields from specific task ID:
+		Long: `Update one or more fields from specific task ID, or from the newest task with TagName:TagValue:
 
 - traggo_cli update taskId [-n | --note "This is a note"]
 - traggo_cli update taskId [-a -n| --append -n "This is a note append to current note"]
 - traggo_cli update taskId [-e | --end-date YYYY/MM/DD]
 - traggo_cli update taskId [-s | --start-date YYYY/MM/DD]
 - traggo_cli update taskId [-t | --tags ""]
+
+Without task id, the task to update is picked among the recent ones.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				return errors.New("this command requiers at least one task id")
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
 			}
-			c := config.LoadConfig(configPath)
 			s := session.NewTraggoSession(c)
+			ctx := cmd.Context()
 			if note != "" && delNote {
 				return errors.New("cannot have --note and --delete-note in same command")
 			}
@@ -47,19 +50,30 @@ var (
 				return nil
 			}
 
-			idStr := strings.TrimSpace(args[0])
-			taskId, err := strconv.Atoi(idStr)
-			if err != nil {
-				return err
+			var task session.GenericTask
+			if len(args) == 0 {
+				picked, err := tui.PickTasks(ctx, s, session.TaskFilter{}, false)
+				if err != nil || len(picked) == 0 {
+					return err
+				}
+				task = picked[0]
+			} else {
+				task, err = findTask(ctx, s, args[0])
+				if err != nil {
+					return err
+				}
+				if task == nil {
+					return fmt.Errorf("unable to retrieve task '%s'", args[0])
+				}
 			}
 
 			// TODO: avoid code duplication...
 			// Update current task
-			currentTimerTask, okTimer := s.SearchTask(taskId).(session.TimerTask)
+			currentTimerTask, okTimer := task.(session.TimerTask)
 			if okTimer {
 				if startDateStr != "" {
 					currentTimerTask.OldStart = currentTimerTask.Start
-					currentTimerTask.Start, err = utils.StrToTime(startDateStr, time.DateTime)
+					currentTimerTask.Start, err = utils.ParseDate(startDateStr, time.Now())
 					if err != nil {
 						return err
 					}
@@ -105,25 +119,24 @@ var (
 					}
 				}
 				fmt.Println(currentTimerTask.PreparePretty(c.Colors))
-				s.UpdateTimerTask(currentTimerTask)
-				return nil
+				return handleQueued(s.UpdateTaskFromContext(ctx, task, currentTimerTask))
 			}
 
 			// Update already done task
-			currentTask, okSpan := s.SearchTask(taskId).(session.TimeSpanTask)
+			currentTask, okSpan := task.(session.TimeSpanTask)
 			if !okSpan {
-				panic("cannot convert task to session.TimeSpanTask")
+				return errors.New("cannot convert task to session.TimeSpanTask")
 			}
 			if startDateStr != "" {
 				currentTask.OldStart = currentTask.Start
-				currentTask.Start, err = utils.StrToTime(startDateStr, time.DateTime)
+				currentTask.Start, err = utils.ParseDate(startDateStr, time.Now())
 				if err != nil {
 					return err
 				}
 				fmt.Println(currentTask.Start)
 			}
 			if endDateStr != "" {
-				currentTask.End, err = utils.StrToTime(endDateStr, time.DateTime)
+				currentTask.End, err = utils.ParseDate(endDateStr, time.Now())
 				if err != nil {
 					return err
 				}
@@ -168,8 +181,7 @@ var (
 				}
 			}
 			fmt.Println(currentTask.PreparePretty(c.Colors))
-			s.UpdateTimeSpanTask(currentTask)
-			return nil
+			return handleQueued(s.UpdateTaskFromContext(ctx, task, currentTask))
 		},
 	}
 )
@@ -180,7 +192,7 @@ func init() {
 	updateCmd.Flags().BoolVarP(&add, "add", "a", false, "Append note to current one. Only useful when using '-n|--note' or '-t|--tags' flags.")
 	updateCmd.Flags().BoolVarP(&delNote, "delete-note", "d", false, "Delete note")
 	updateCmd.Flags().StringVarP(&note, "note", "n", "", "Note to add to task ID")
-	updateCmd.Flags().StringVarP(&startDateStr, "start-date", "s", "", "Task new start date")
-	updateCmd.Flags().StringVarP(&endDateStr, "end-date", "e", "", "Task new end date")
+	updateCmd.Flags().StringVarP(&startDateStr, "start-date", "s", "", "Task new start date: "+utils.DateHelp)
+	updateCmd.Flags().StringVarP(&endDateStr, "end-date", "e", "", "Task new end date: "+utils.DateHelp)
 
 }
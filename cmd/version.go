@@ -12,10 +12,18 @@ import (
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Display Traggo version",
-	Run: func(cmd *cobra.Command, args []string) {
-		c := config.LoadConfig(configPath)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := config.LoadConfig(configPath)
+		if err != nil {
+			return err
+		}
 		s := session.NewTraggoSession(c)
-		fmt.Println(s.Version())
+		version, err := s.VersionContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		fmt.Println(version)
+		return nil
 	},
 }
 
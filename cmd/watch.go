@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	config "github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+	utils "github.com/kalidor/traggo_cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval    time.Duration
+	watchMaxDuration time.Duration
+	watchEndOfDay    string
+	watchCommand     string
+	watchNotify      bool
+	watchStop        bool
+	watchOnce        bool
+
+	// watchCmd represents the watch command
+	watchCmd = &cobra.Command{
+		Use:   "watch",
+		Short: "Warn about forgotten timers",
+		Long: `Check the running timers every interval, until interrupted. A timer running
+for longer than --max-duration, or still running at --end-of-day, is reported
+once, even across runs (watch.state in configuration file): it is printed, then
+each hook is run. If a hook fails, the timer is reported again by the next check,
+and --once exits with an error:
+- --command runs a shell command, the timer being given in the environment:
+  TRAGGO_TIMER_ID, TRAGGO_TIMER_TAGS, TRAGGO_TIMER_NOTE, TRAGGO_TIMER_START,
+  TRAGGO_REASON (max-duration or end-of-day) and TRAGGO_AT (when the limit was reached)
+- --notify shows a desktop notification
+- --stop stops the timer when the limit was reached, its last activity
+
+Defaults come from the watch section of the configuration file.
+
+- traggo_cli watch --end-of-day 19:00 --notify
+- traggo_cli watch --max-duration 4h --command 'mail -s "$TRAGGO_TIMER_TAGS" me@example.com </dev/null'
+- traggo_cli watch --once --end-of-day 20:00 --stop`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			w, interval, err := newWatcher(cmd, c)
+			if err != nil {
+				return err
+			}
+			if watchOnce {
+				_, err := w.Check(cmd.Context())
+				return err
+			}
+			printInfo("Checking timers every %s, Ctrl+C to quit\n", interval)
+			return w.Watch(cmd.Context(), interval)
+		},
+	}
+)
+
+// newWatcher reads the flags, defaulting to the configuration then to the
+// default values
+func newWatcher(cmd *cobra.Command, c *config.Config) (*session.Watcher, time.Duration, error) {
+	def := c.Watch
+	changed := cmd.Flags().Changed
+
+	interval := watchInterval
+	if !changed("interval") && def.Interval.Duration != 0 {
+		interval = def.Interval.Duration
+	}
+	if interval <= 0 {
+		return nil, 0, fmt.Errorf("invalid interval %s, it must be positive", interval)
+	}
+	w := &session.Watcher{
+		Session:     session.NewTraggoSession(c),
+		MaxDuration: watchMaxDuration,
+		AutoStop:    watchStop || !changed("stop") && def.Stop,
+		StatePath:   def.State,
+		Report: func(alert session.WatchAlert, err error) {
+			if alert.Reason == "" {
+				fmt.Fprintf(os.Stderr, "%s %v\n", session.TimeNow().Format(time.DateTime), err)
+				return
+			}
+			fmt.Println(alert)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "timer %d: %v\n", alert.Timer.Id, err)
+			}
+		},
+	}
+	if !changed("max-duration") && def.MaxDuration.Duration != 0 {
+		w.MaxDuration = def.MaxDuration.Duration
+	}
+	endOfDay := watchEndOfDay
+	if !changed("end-of-day") && def.EndOfDay != "" {
+		endOfDay = def.EndOfDay
+	}
+	if endOfDay != "" {
+		var err error
+		w.EndOfDay, err = utils.ParseClock(endOfDay)
+		if err != nil {
+			return nil, 0, err
+		}
+		if w.EndOfDay == 0 {
+			// midnight ends the day
+			w.EndOfDay = 24 * time.Hour
+		}
+	}
+	if w.MaxDuration <= 0 && w.EndOfDay == 0 {
+		return nil, 0, fmt.Errorf("nothing to watch, give --max-duration or --end-of-day")
+	}
+
+	command := watchCommand
+	if !changed("command") && def.Command != "" {
+		command = def.Command
+	}
+	if command != "" {
+		w.Notifiers = append(w.Notifiers, session.CommandNotifier{Command: command})
+	}
+	if watchNotify || !changed("notify") && def.Notify {
+		w.Notifiers = append(w.Notifiers, session.DesktopNotifier{})
+	}
+	return w, interval, nil
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", config.DefaultWatchInterval, "Delay between two checks of the timers")
+	watchCmd.Flags().DurationVar(&watchMaxDuration, "max-duration", config.DefaultMaxTimer, "Timers running longer are reported, 0 to ignore the duration")
+	watchCmd.Flags().StringVar(&watchEndOfDay, "end-of-day", "", "Timers still running at this time (hh:mm, 00:00 for midnight) are reported")
+	watchCmd.Flags().StringVar(&watchCommand, "command", "", "Shell command run for each reported timer")
+	watchCmd.Flags().BoolVar(&watchNotify, "notify", false, "Show a desktop notification for each reported timer")
+	watchCmd.Flags().BoolVar(&watchStop, "stop", false, "Stop reported timers when the limit was reached")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Check the timers once then quit, to run from cron")
+}
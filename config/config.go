@@ -2,13 +2,37 @@ package config
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Default values used to request Traggo when not provided in configuration file
+const (
+	DefaultTimeout = 30 * time.Second
+	DefaultRetries = 2
+	DefaultBackoff = 500 * time.Millisecond
+
+	DefaultCacheTTL         = time.Minute
+	DefaultCacheFullRefresh = 24 * time.Hour
+
+	DefaultJournalSize = 100
+
+	DefaultWorkHours = "09:00-18:00"
+	DefaultLintGap   = 15 * time.Minute
+	DefaultMaxTimer  = 10 * time.Hour
+
+	DefaultWatchInterval = 5 * time.Minute
+
+	DefaultStatusTTL      = 15 * time.Second
+	DefaultStatusTimeout  = 2 * time.Second
+	DefaultStatusTemplate = "{{.Tags}} {{.Elapsed}}"
+)
+
 type Auth struct {
 	Url   string `json:"url"`   // endpoint URL
 	Token string `json:"token"` // Token is retrieved the very first time then store in configuration. All future calls will use it
@@ -51,41 +75,150 @@ func (a ByPosition) Len() int           { return len(a) }
 func (a ByPosition) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a ByPosition) Less(i, j int) bool { return a[i].Position < a[j].Position }
 
+// Duration is a time.Duration written as a string ("30s", "1m30s") in configuration file
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	d.Duration, err = time.ParseDuration(s)
+	return err
+}
+
+// ClientDef defines how requests are sent to Traggo
+type ClientDef struct {
+	Timeout Duration `json:"timeout,omitzero"` // maximum duration of one request
+	Retries int      `json:"retries"`          // how many times a failing query is retried. Mutations are never retried
+	Backoff Duration `json:"backoff,omitzero"` // delay before the first retry, doubled on each new retry
+}
+
+// OfflineDef defines where mutations are kept when Traggo is unreachable
+type OfflineDef struct {
+	Queue string `json:"queue,omitempty"` // queue file, default to queue.json next to configuration file
+}
+
+// CacheDef defines the local cache of time spans
+type CacheDef struct {
+	Path        string   `json:"path,omitempty"`       // cache file, default to cache.json next to configuration file
+	TTL         Duration `json:"ttl,omitzero"`         // cached tasks are refreshed from Traggo after this delay
+	FullRefresh Duration `json:"fullRefresh,omitzero"` // the whole history is downloaded again after this delay
+	Disabled    bool     `json:"disabled,omitempty"`
+}
+
+// ExportDef defines how tasks are exported to other tools
+type ExportDef struct {
+	SummaryTags []string `json:"summaryTags,omitempty"` // tag names whose values make the summary of calendar events
+}
+
+// UndoDef defines where deleted tasks and the journal of mutations are saved
+// so they can be recreated or reverted
+type UndoDef struct {
+	Dir      string `json:"dir,omitempty"`      // default to undo directory next to configuration file
+	Size     int    `json:"size,omitempty"`     // number of operations kept in the journal
+	Disabled bool   `json:"disabled,omitempty"` // no journal, undo is not possible
+}
+
+// LintDef defines what 'traggo_cli lint' reports
+type LintDef struct {
+	WorkHours string   `json:"workHours,omitempty"` // hh:mm-hh:mm, gaps are only looked for inside
+	Gap       Duration `json:"gap,omitzero"`        // shortest gap reported
+	MaxTimer  Duration `json:"maxTimer,omitzero"`   // timers running longer are reported
+}
+
+// WatchDef defines when 'traggo_cli watch' reports a forgotten timer and what
+// it does then
+type WatchDef struct {
+	Interval    Duration `json:"interval,omitzero"`    // delay between two checks of the timers
+	MaxDuration Duration `json:"maxDuration,omitzero"` // timers running longer are reported
+	EndOfDay    string   `json:"endOfDay,omitempty"`   // hh:mm, timers still running then are reported
+	Command     string   `json:"command,omitempty"`    // shell command run for each reported timer
+	Notify      bool     `json:"notify,omitempty"`     // show a desktop notification for each reported timer
+	Stop        bool     `json:"stop,omitempty"`       // stop reported timers when the limit was reached
+	State       string   `json:"state,omitempty"`      // file of the reported timers, default to watch.json next to configuration file
+}
+
+// StatusDef defines how 'traggo_cli status' shows the running timer
+type StatusDef struct {
+	Path     string   `json:"path,omitempty"`     // cache file of the running timers, default to status.json next to configuration file
+	TTL      Duration `json:"ttl,omitzero"`       // cached timers are used without asking Traggo during this delay
+	Timeout  Duration `json:"timeout,omitzero"`   // maximum duration of the request refreshing the cache, without retry
+	Template string   `json:"template,omitempty"` // text/template of the status, see 'traggo_cli status --help'
+	Idle     string   `json:"idle,omitempty"`     // status without running timer
+}
+
 // Config contains all configuration related information
 type Config struct {
-	Auth   Auth      `json:"auth"`   // use for authentication
-	Colors ColorsDef `json:"colors"` // use for user experience, to colorize output for matching tags
-	Tags   TagsDef   `json:"tags"`   // use for user experience, to specify how many tags should be proposed in "live" mode
+	Auth    Auth       `json:"auth"`    // use for authentication
+	Colors  ColorsDef  `json:"colors"`  // use for user experience, to colorize output for matching tags
+	Tags    TagsDef    `json:"tags"`    // use for user experience, to specify how many tags should be proposed in "live" mode
+	Client  ClientDef  `json:"client"`  // use for network, to specify timeout and retry policy
+	Offline OfflineDef `json:"offline"` // use for network, to queue mutations when Traggo is unreachable
+	Cache   CacheDef   `json:"cache"`   // use for speed, to avoid downloading all time spans on each search
+	Export  ExportDef  `json:"export"`  // use for export, to shape calendar events
+	Undo    UndoDef    `json:"undo"`    // use for safety, to keep deleted tasks
+	Lint    LintDef    `json:"lint"`    // use for data quality, to find forgotten or overlapping tasks
+	Watch   WatchDef   `json:"watch"`   // use for data quality, to be warned about forgotten timers
+	Status  StatusDef  `json:"status"`  // use for shell prompts and status bars, to show the running timer
+}
+
+func defaultClient() ClientDef {
+	return ClientDef{
+		Timeout: Duration{DefaultTimeout},
+		Retries: DefaultRetries,
+		Backoff: Duration{DefaultBackoff},
+	}
 }
 
 func NewConfig(url, token string) *Config {
 	return &Config{
-		Auth{Url: url, Token: token},
-		ColorsDef{},
-		TagsDef{},
+		Auth:   Auth{Url: url, Token: token},
+		Colors: ColorsDef{},
+		Tags:   TagsDef{},
+		Client: defaultClient(),
 	}
 }
 
-func LoadConfig(configPath string) *Config {
-	var c Config
+func LoadConfig(configPath string) (*Config, error) {
+	// default values are kept when missing from configuration file
+	c := Config{Client: defaultClient()}
 	d, err := os.ReadFile(configPath)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	err = json.Unmarshal(d, &c)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("invalid configuration file '%s': %w", configPath, err)
+	}
+	if c.Offline.Queue == "" {
+		c.Offline.Queue = filepath.Join(filepath.Dir(configPath), "queue.json")
+	}
+	if c.Cache.Path == "" {
+		c.Cache.Path = filepath.Join(filepath.Dir(configPath), "cache.json")
+	}
+	if c.Watch.State == "" {
+		c.Watch.State = filepath.Join(filepath.Dir(configPath), "watch.json")
+	}
+	if c.Status.Path == "" {
+		c.Status.Path = filepath.Join(filepath.Dir(configPath), "status.json")
+	}
+	if c.Undo.Dir == "" {
+		c.Undo.Dir = filepath.Join(filepath.Dir(configPath), "undo")
 	}
-	return &c
+	return &c, nil
 }
 
 // TODO: to remove, because not used
 func NewConfigToken(url string, token string) *Config {
-	return &Config{
-		Auth{Url: url, Token: token},
-		ColorsDef{},
-		TagsDef{},
-	}
+	return NewConfig(url, token)
 }
 
 func (c *Config) Save(configPath string) error {
@@ -96,16 +229,9 @@ func (c *Config) Save(configPath string) error {
 		return err
 	}
 
-	f, err := os.Create(configPath)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer f.Close()
-
 	data, err := json.Marshal(c)
 	if err != nil {
 		return err
 	}
-	f.Write(data)
-	return nil
+	return os.WriteFile(configPath, data, 0o600)
 }
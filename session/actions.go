@@ -1,11 +1,12 @@
 package session
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
-
-	"github.com/kalidor/traggo_cli/config"
 )
 
 var TimeNow = time.Now
@@ -13,12 +14,30 @@ var TimeNow = time.Now
 type createTimeSpanData struct {
 	Data TimerTask `json:"createTimeSpan"`
 }
-type createTimeSpanRoot struct {
-	Data   createTimeSpanData `json:"data"`
-	Errors []Error            `json:"errors"`
+
+type createdTimeSpanData struct {
+	Data TimeSpanTask `json:"createTimeSpan"`
+}
+
+type stopTimeSpanData struct {
+	Data TimeSpanTask `json:"stopTimeSpan"`
+}
+
+type removeTimeSpanData struct {
+	Data *TimeSpanTask `json:"removeTimeSpan"`
+}
+
+type updateTimeSpanData struct {
+	Data TimeSpanTask `json:"updateTimeSpan"`
+}
+
+type copyTimeSpanData struct {
+	Data TimerTask `json:"copyTimeSpan"`
 }
 
-func (t *Traggo) Start(tags []string, note string) {
+// ParseTags converts tagName:tagValue strings to tags, ignoring the ones
+// without value
+func ParseTags(tags []string) []Tag {
 	var genTags []Tag
 	for _, tag := range tags {
 		if strings.Contains(tag, ":") {
@@ -26,90 +45,278 @@ func (t *Traggo) Start(tags []string, note string) {
 			genTags = append(genTags, Tag{Key: s[0], Value: s[1]})
 		}
 	}
+	return genTags
+}
+
+// Start a new timer with provided tags (tagName:tagValue) and note
+func (t *Traggo) Start(tags []string, note string) (TimerTask, error) {
+	return t.StartAtContext(context.Background(), tags, note, time.Time{})
+}
+
+func (t *Traggo) StartContext(ctx context.Context, tags []string, note string) (TimerTask, error) {
+	return t.StartAtContext(ctx, tags, note, time.Time{})
+}
+
+// StartAt starts a new timer as Start, from a time in the past (now if zero).
+// See CheckOverlap to avoid overlapping existing time spans.
+func (t *Traggo) StartAt(tags []string, note string, start time.Time) (TimerTask, error) {
+	return t.StartAtContext(context.Background(), tags, note, start)
+}
+
+func (t *Traggo) StartAtContext(ctx context.Context, tags []string, note string, start time.Time) (TimerTask, error) {
+	if start.IsZero() {
+		start = TimeNow()
+	}
+	if start.After(TimeNow()) {
+		return TimerTask{}, errors.New("a timer cannot start in the future")
+	}
+	task, err := t.startTimer(ctx, ParseTags(tags), note, start)
+	if err != nil {
+		return task, err
+	}
+	after := asTimeSpan(task)
+	t.Journal.record(OpStart, JournalChange{After: &after})
+	return task, nil
+}
 
+// startTimer starts a timer without recording it in the journal
+func (t *Traggo) startTimer(ctx context.Context, tags []Tag, note string, start time.Time) (TimerTask, error) {
 	variables := struct {
 		Start time.Time `json:"start"`
 		Tags  []Tag     `json:"tags"`
 		Note  string    `json:"note"`
 	}{
-		Start: TimeNow().Local(),
-		Tags:  genTags,
+		Start: start.Local(),
+		Tags:  tags,
 		Note:  note,
 	}
 
-	op := Operation{
-		OperationName: "StartTimer",
-		Variables:     variables,
-		Query:         "mutation StartTimer($start: Time!, $tags: [InputTimeSpanTag!], $note: String!) {\n  createTimeSpan(start: $start, tags: $tags, note: $note) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}\n",
+	d, err := mutate[createTimeSpanData](ctx, t, qStartTimer, variables)
+	if errors.Is(err, ErrQueued) {
+		// the timer will get its id once synced
+		return TimerTask{Start: variables.Start, Tags: tags, Note: note}, err
+	}
+	if err != nil {
+		return TimerTask{}, err
 	}
+	t.Cache.Invalidate()
+	return d.Data, nil
+}
+
+// CreateTimeSpan creates an already done task with explicit start and end
+func (t *Traggo) CreateTimeSpan(task TimeSpanTask) (TimeSpanTask, error) {
+	return t.CreateTimeSpanContext(context.Background(), task)
+}
 
-	// Parse http.Response Boby as JSON and display it
-	var d createTimeSpanRoot
-	err := t.Request(
-		"Start",
-		"POST",
-		op,
-		&d,
-	)
+func (t *Traggo) CreateTimeSpanContext(ctx context.Context, task TimeSpanTask) (TimeSpanTask, error) {
+	variables := struct {
+		Start time.Time `json:"start"`
+		End   time.Time `json:"end"`
+		Tags  []Tag     `json:"tags"`
+		Note  string    `json:"note"`
+	}{
+		Start: task.Start,
+		End:   task.End,
+		Tags:  task.Tags,
+		Note:  task.Note,
+	}
+	d, err := mutate[createdTimeSpanData](ctx, t, qCreateTimeSpan, variables)
+	if errors.Is(err, ErrQueued) {
+		// the time span will get its id once synced
+		task.Id = 0
+		return task, err
+	}
 	if err != nil {
-		log.Fatal(err)
+		return TimeSpanTask{}, err
+	}
+	t.Cache.put(d.Data)
+	t.Journal.record(OpCreate, JournalChange{After: &d.Data})
+	return d.Data, nil
+}
+
+// Stop given timers and return the resulting time spans
+func (t *Traggo) Stop(ids []int) (TimeSpanTaskList, error) {
+	return t.StopAtContext(context.Background(), ids, time.Time{})
+}
+
+func (t *Traggo) StopContext(ctx context.Context, ids []int) (TimeSpanTaskList, error) {
+	return t.StopAtContext(ctx, ids, time.Time{})
+}
+
+// StopAt stops given timers as Stop, at a time in the past (now if zero).
+// Nothing is stopped if a timer started after end.
+func (t *Traggo) StopAt(ids []int, end time.Time) (TimeSpanTaskList, error) {
+	return t.StopAtContext(context.Background(), ids, end)
+}
+
+func (t *Traggo) StopAtContext(ctx context.Context, ids []int, end time.Time) (TimeSpanTaskList, error) {
+	if !end.IsZero() {
+		if end.After(TimeNow()) {
+			return nil, errors.New("a timer cannot stop in the future")
+		}
+		timers, err := t.ListCurrentTasksContext(ctx)
+		// when unreachable, stopping is queued and Traggo will check
+		if err != nil && !errors.Is(err, ErrUnreachable) {
+			return nil, err
+		}
+		for _, timer := range timers.Timers {
+			if slices.Contains(ids, timer.Id) && end.Before(timer.Start) {
+				return nil, fmt.Errorf("timer %d started at %s, after %s", timer.Id, timer.Start.Local().Format(time.DateTime), end.Local().Format(time.DateTime))
+			}
+		}
 	}
-	d.Data.Data.PreparePretty(t.Colors)
 
+	stopped, changes, err := t.stopTimers(ctx, ids, end)
+	// stopped timers are recorded as one operation, even on error
+	t.Journal.record(OpStop, changes...)
+	return stopped, err
 }
 
-func (t *Traggo) Stop(colors config.ColorsDef, ids []int) {
+// stopTimers stops timers without recording them in the journal, and
+// returns their changes. ErrQueued is returned once all timers are handled.
+func (t *Traggo) stopTimers(ctx context.Context, ids []int, end time.Time) (TimeSpanTaskList, []JournalChange, error) {
 	variables := struct {
 		Id  int       `json:"id"`
 		End time.Time `json:"end"`
 	}{
-		Id:  0,
-		End: TimeNow().Local(),
-	}
-	op := Operation{
-		OperationName: "StopTimer",
-		Variables:     variables,
-		Query:         "mutation StopTimer($id: Int!, $end: Time!) {\n  stopTimeSpan(id: $id, end: $end) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}\n",
+		Id: 0,
 	}
 
+	var stopped TimeSpanTaskList
+	var changes []JournalChange
+	var queued error
 	for _, id := range ids {
 		variables.Id = id
-		variables.End = TimeNow().Local()
-
-		op.Variables = &variables
-		var d TimeSpanTask
-		err := t.Request(
-			"ListBetweenDates",
-			"POST",
-			op,
-			&d,
-		)
+		variables.End = end.Local()
+		if end.IsZero() {
+			variables.End = TimeNow().Local()
+		}
+
+		d, err := mutate[stopTimeSpanData](ctx, t, qStopTimer, variables)
+		if errors.Is(err, ErrQueued) {
+			queued = err
+			continue
+		}
 		if err != nil {
-			log.Fatal(err)
+			return stopped, changes, err
+		}
+		stopped = append(stopped, d.Data)
+		t.Cache.put(d.Data)
+		before, after := d.Data, d.Data
+		before.End = time.Time{}
+		changes = append(changes, JournalChange{Before: &before, After: &after})
+	}
+	return stopped, changes, queued
+}
+
+// Switch stops given timers and starts a new one at the very same time, with
+// provided tags (tagName:tagValue) and note. If the new timer cannot be
+// started, the stopped timers run again. Everything is a single operation of
+// the journal.
+func (t *Traggo) Switch(ids []int, tags []string, note string) (TimeSpanTaskList, TimerTask, error) {
+	return t.SwitchContext(context.Background(), ids, tags, note)
+}
+
+func (t *Traggo) SwitchContext(ctx context.Context, ids []int, tags []string, note string) (TimeSpanTaskList, TimerTask, error) {
+	now := TimeNow()
+	stopped, changes, err := t.stopTimers(ctx, ids, now)
+	queued := err
+	var started TimerTask
+	if err == nil || errors.Is(err, ErrQueued) {
+		started, err = t.startTimer(ctx, ParseTags(tags), note, now)
+	}
+	switch {
+	case err == nil:
+		after := asTimeSpan(started)
+		changes = append(changes, JournalChange{After: &after})
+		t.Journal.record(OpSwitch, changes...)
+		return stopped, started, queued
+	case errors.Is(err, ErrQueued):
+		t.Journal.record(OpSwitch, changes...)
+		return stopped, started, err
+	}
+
+	// restart the stopped timers, newest change first
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		_, rErr := t.applyState(ctx, c.After, c.Before)
+		if rErr != nil {
+			t.Journal.record(OpStop, changes[:i+1]...)
+			return stopped[:i+1], TimerTask{}, fmt.Errorf("%w, and timer %d stays stopped: %w", err, c.After.Id, rErr)
 		}
-		d.PreparePretty(colors)
+		t.cacheState(c.After, c.Before)
+	}
+	return nil, TimerTask{}, err
+}
+
+func (t *Traggo) Delete(ids []int) error {
+	return t.DeleteContext(context.Background(), ids)
+}
+
+func (t *Traggo) DeleteContext(ctx context.Context, ids []int) error {
+	// the deleted tasks are recorded as returned by Traggo
+	return t.deleteTasks(ctx, ids, nil)
+}
+
+// DeleteTasks deletes tasks as Delete, recording them in the journal as given
+func (t *Traggo) DeleteTasks(tasks []GenericTask) error {
+	return t.DeleteTasksContext(context.Background(), tasks)
+}
+
+func (t *Traggo) DeleteTasksContext(ctx context.Context, tasks []GenericTask) error {
+	ids := make([]int, len(tasks))
+	before := map[int]TimeSpanTask{}
+	for i, task := range tasks {
+		ids[i] = task.GetId()
+		before[ids[i]] = asTimeSpan(task)
 	}
+	return t.deleteTasks(ctx, ids, before)
 }
 
-func (t *Traggo) Delete(ids []int) {
+// deleteTasks deletes ids, recording their state from before, or from the
+// response of Traggo
+func (t *Traggo) deleteTasks(ctx context.Context, ids []int, before map[int]TimeSpanTask) error {
 	variables := struct {
 		Id int `json:"id"`
 	}{
 		Id: 0,
 	}
-	op := Operation{
-		OperationName: "RemoveTimeSpan",
-		Variables:     variables,
-		Query:         "mutation RemoveTimeSpan($id: Int!) {\n  removeTimeSpan(id: $id) {\n    id\n    __typename\n  }\n}\n",
-	}
+	var changes []JournalChange
+	// deleted tasks are recorded as one operation, even on error
+	defer func() { t.Journal.record(OpDelete, changes...) }()
+	var queued error
 	for _, id := range ids {
 		variables.Id = id
-		op.Variables = variables
-		t.Request("RemoveTimeSpan", "POST", op, nil)
+		d, err := mutate[removeTimeSpanData](ctx, t, qRemoveTimeSpan, variables)
+		if errors.Is(err, ErrQueued) {
+			queued = err
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		t.Cache.forget(id)
+		b, known := before[id]
+		if !known && d.Data != nil && !d.Data.Start.IsZero() {
+			b, known = *d.Data, true
+		}
+		if known {
+			changes = append(changes, JournalChange{Before: &b})
+		}
 	}
+	return queued
+}
+
+func (t *Traggo) UpdateTimerTask(task TimerTask) error {
+	return t.UpdateTimerTaskContext(context.Background(), task)
+}
+
+func (t *Traggo) UpdateTimerTaskContext(ctx context.Context, task TimerTask) error {
+	return t.updateTask(ctx, t.journalTasks(ctx, task.Id), task)
 }
 
-func (t *Traggo) UpdateTimerTask(task TimerTask) {
+// updateTimerTask sends the update of a timer and returns its new state
+func (t *Traggo) updateTimerTask(ctx context.Context, task TimerTask) (TimeSpanTask, error) {
 	variables := struct {
 		OldStart time.Time `json:"oldStart,omitzero"`
 		Id       int       `json:"id,omitempty"`
@@ -123,17 +330,57 @@ func (t *Traggo) UpdateTimerTask(task TimerTask) {
 		Tags:     task.Tags,
 		Note:     task.Note,
 	}
-
-	op := Operation{
-		OperationName: "UpdateTimeSpan",
-		Variables:     variables,
-		Query:         "mutation UpdateTimeSpan($id: Int!, $start: Time!, $tags: [InputTimeSpanTag!], $note: String!) {\n  updateTimeSpan(id: $id, start: $start, tags: $tags, note: $note) {\n    id\n    start\n    tags {\n      key\n      value\n      __typename\n    }\n   note\n    __typename\n  }\n}\n",
+	_, err := mutate[updateTimeSpanData](ctx, t, qUpdateTimer, variables)
+	if err != nil {
+		return TimeSpanTask{}, err
 	}
-	t.Request("UpdateTimeSpan", "POST", op, nil)
+	t.Cache.Invalidate()
+	after := asTimeSpan(task)
+	after.OldStart = time.Time{}
+	return after, nil
+}
+
+func (t *Traggo) UpdateTimeSpanTask(task TimeSpanTask) error {
+	return t.UpdateTimeSpanTaskContext(context.Background(), task)
+}
+
+func (t *Traggo) UpdateTimeSpanTaskContext(ctx context.Context, task TimeSpanTask) error {
+	return t.updateTask(ctx, t.journalTasks(ctx, task.Id), task)
+}
+
+// UpdateTaskFrom updates task, a timer or a time span, whose state before the
+// update is before, as recorded in the journal
+func (t *Traggo) UpdateTaskFrom(before, task GenericTask) error {
+	return t.UpdateTaskFromContext(context.Background(), before, task)
+}
+
+func (t *Traggo) UpdateTaskFromContext(ctx context.Context, before, task GenericTask) error {
+	return t.updateTask(ctx, map[int]TimeSpanTask{before.GetId(): asTimeSpan(before)}, task)
+}
 
+// updateTask sends the update of task, recorded if its state is in before
+func (t *Traggo) updateTask(ctx context.Context, before map[int]TimeSpanTask, task GenericTask) error {
+	var after TimeSpanTask
+	var err error
+	switch task := task.(type) {
+	case TimerTask:
+		after, err = t.updateTimerTask(ctx, task)
+	case TimeSpanTask:
+		after, err = t.updateTimeSpanTask(ctx, task)
+	default:
+		return fmt.Errorf("cannot update task of type %T", task)
+	}
+	if err != nil {
+		return err
+	}
+	if change, ok := updated(before, after); ok {
+		t.Journal.record(OpUpdate, change)
+	}
+	return nil
 }
 
-func (t *Traggo) UpdateTimeSpanTask(task TimeSpanTask) {
+// updateTimeSpanTask sends the update of a time span and returns its new state
+func (t *Traggo) updateTimeSpanTask(ctx context.Context, task TimeSpanTask) (TimeSpanTask, error) {
 	variables := struct {
 		OldStart time.Time `json:"oldStart,omitzero"`
 		Id       int       `json:"id,omitempty"`
@@ -149,27 +396,78 @@ func (t *Traggo) UpdateTimeSpanTask(task TimeSpanTask) {
 		Tags:     task.Tags,
 		Note:     task.Note,
 	}
-	op := Operation{
-		OperationName: "UpdateTimeSpan",
-		Variables:     variables,
-		Query:         "mutation UpdateTimeSpan($id: Int!, $start: Time!, $end: Time, $tags: [InputTimeSpanTag!], $oldStart: Time, $note: String!) {\n  updateTimeSpan(id: $id, start: $start, end: $end, tags: $tags, oldStart: $oldStart, note: $note) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}\n",
+	d, err := mutate[updateTimeSpanData](ctx, t, qUpdateTimeSpan, variables)
+	if err != nil {
+		return TimeSpanTask{}, err
 	}
-	t.Request("UpdateTimeSpanTask", "POST", op, nil)
+	t.Cache.put(d.Data)
+	return d.Data, nil
+}
+
+// Continue starts a new timer with the tags and note of task
+func (t *Traggo) Continue(task GenericTask) error {
+	_, err := t.ContinueWithContext(context.Background(), task, nil, "")
+	return err
 }
 
-func (t *Traggo) Continue(task GenericTask) {
+func (t *Traggo) ContinueContext(ctx context.Context, task GenericTask) error {
+	_, err := t.ContinueWithContext(ctx, task, nil, "")
+	return err
+}
+
+// ContinueWith starts a new timer as Continue, and returns it. Tags of task
+// are replaced by the ones of tags (tagName:tagValue) with the same name,
+// the others are added. The note is replaced if not empty.
+func (t *Traggo) ContinueWith(task GenericTask, tags []string, note string) (TimerTask, error) {
+	return t.ContinueWithContext(context.Background(), task, tags, note)
+}
+
+func (t *Traggo) ContinueWithContext(ctx context.Context, task GenericTask, tags []string, note string) (TimerTask, error) {
+	copied := asTimeSpan(task).TimerTask
+	copied.Id, copied.Start, copied.OldStart = 0, TimeNow(), time.Time{}
+	if len(tags) > 0 || note != "" {
+		// Traggo copies time spans as they are
+		copied.Tags = mergeTags(copied.Tags, ParseTags(tags))
+		if note != "" {
+			copied.Note = note
+		}
+		started, err := t.startTimer(ctx, copied.Tags, copied.Note, copied.Start)
+		if err == nil {
+			after := asTimeSpan(started)
+			t.Journal.record(OpContinue, JournalChange{After: &after})
+		}
+		return started, err
+	}
+
 	variables := struct {
 		Id    int       `json:"id,omitempty"`
 		Start time.Time `json:"start"`
 	}{
 		Id:    task.GetId(),
-		Start: TimeNow(),
+		Start: copied.Start,
+	}
+	d, err := mutate[copyTimeSpanData](ctx, t, qContinue, variables)
+	if errors.Is(err, ErrQueued) {
+		// the timer will get its id once synced
+		return copied, err
 	}
-	op := Operation{
-		OperationName: "Continue",
-		Variables:     variables,
-		Query:         "mutation Continue($id: Int!, $start: Time!) {\n  copyTimeSpan(id: $id, start: $start) {\n    id\n    start\n    __typename\n  }\n}",
+	if err != nil {
+		return TimerTask{}, err
 	}
-	t.Request("Continue", "POST", op, nil)
+	t.Cache.Invalidate()
+	after := asTimeSpan(d.Data)
+	t.Journal.record(OpContinue, JournalChange{After: &after})
+	return d.Data, nil
+}
 
+// mergeTags returns tags with the values of overrides for the same names,
+// followed by the other overrides
+func mergeTags(tags, overrides []Tag) []Tag {
+	var merged []Tag
+	for _, tag := range tags {
+		if !slices.ContainsFunc(overrides, func(o Tag) bool { return o.Key == tag.Key }) {
+			merged = append(merged, tag)
+		}
+	}
+	return append(merged, overrides...)
 }
@@ -0,0 +1,239 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ArchiveVersion is the format version written by Backup. Archives with a
+// newer version are refused by ReadArchive.
+const ArchiveVersion = 1
+
+// ErrNotEmpty is returned by Restore when the account already has tasks
+var ErrNotEmpty = errors.New("account is not empty")
+
+// Archive is a full copy of a Traggo account
+type Archive struct {
+	Version    int              `json:"version"`
+	Created    time.Time        `json:"created"`
+	Traggo     string           `json:"traggo"` // version of the server the archive comes from
+	Tags       []TagDefinition  `json:"tags"`
+	Settings   UserSettingsData `json:"settings"`
+	Dashboards []Dashboard      `json:"dashboards"`
+	Timers     []TimerTask      `json:"timers"`
+	TimeSpans  TimeSpanTaskList `json:"timeSpans"`
+}
+
+// ReadArchive decodes an archive written by Archive.Write
+func ReadArchive(r io.Reader) (Archive, error) {
+	var a Archive
+	err := json.NewDecoder(r).Decode(&a)
+	if err != nil {
+		return Archive{}, fmt.Errorf("invalid archive: %w", err)
+	}
+	if a.Version == 0 {
+		return Archive{}, fmt.Errorf("invalid archive: no version")
+	}
+	if a.Version > ArchiveVersion {
+		return Archive{}, fmt.Errorf("archive version %d is not supported, upgrade traggo_cli", a.Version)
+	}
+	return a, nil
+}
+
+func (a Archive) Write(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(a)
+}
+
+// Backup downloads everything needed to rebuild the account: time spans,
+// current timers, tags, user settings and dashboards
+func (t *Traggo) Backup() (Archive, error) {
+	return t.BackupContext(context.Background())
+}
+
+func (t *Traggo) BackupContext(ctx context.Context) (Archive, error) {
+	a := Archive{Version: ArchiveVersion, Created: TimeNow()}
+	version, err := t.GetVersionContext(ctx)
+	if err != nil {
+		return Archive{}, err
+	}
+	a.Traggo = version.Name
+
+	a.Tags, err = t.GetTagsContext(ctx)
+	if err != nil {
+		return Archive{}, err
+	}
+	a.Settings, err = t.GetSettingsContext(ctx)
+	if err != nil {
+		return Archive{}, err
+	}
+	a.Dashboards, err = t.GetDashboardsContext(ctx)
+	if err != nil {
+		return Archive{}, err
+	}
+	timers, err := t.ListCurrentTasksContext(ctx)
+	if err != nil {
+		return Archive{}, err
+	}
+	a.Timers = timers.Timers
+	a.TimeSpans, err = t.ListCompleteTasksContext(ctx)
+	if err != nil {
+		return Archive{}, err
+	}
+	if a.TimeSpans == nil {
+		a.TimeSpans = TimeSpanTaskList{}
+	}
+	return a, nil
+}
+
+// RestoreReport describes what Restore created
+type RestoreReport struct {
+	Tags       int         // tags created, the ones already known are kept
+	Dashboards int         // dashboards created
+	Ids        map[int]int // id in the archive => id of the new task
+}
+
+// isEmpty tells if the account has neither timer nor time span
+func (t *Traggo) isEmpty(ctx context.Context) (bool, error) {
+	timers, err := t.ListCurrentTasksContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	empty := len(timers.Timers) == 0
+	err = t.eachTimeSpansPage(ctx, func(page TimeSpans) bool {
+		empty = empty && len(page.TimeSpans) == 0
+		return false
+	})
+	return empty, err
+}
+
+// Restore replays archive into the account: tags, settings, time spans,
+// timers then dashboards. Tasks get new ids, see RestoreReport.Ids.
+// Unless force is set, ErrNotEmpty is returned if the account already has tasks,
+// as restoring twice would duplicate them.
+// Nothing is queued when Traggo is unreachable: the restore stops at the first
+// error and the report tells what was already created, see ResumeRestore.
+func (t *Traggo) Restore(archive Archive, force bool) (RestoreReport, error) {
+	return t.RestoreContext(context.Background(), archive, force)
+}
+
+func (t *Traggo) RestoreContext(ctx context.Context, archive Archive, force bool) (RestoreReport, error) {
+	return t.restore(ctx, archive, force, RestoreReport{})
+}
+
+// ResumeRestore continues an interrupted restore of archive, previous being
+// the report of the failed Restore: the tasks and dashboards it created are
+// skipped. The returned report includes them.
+func (t *Traggo) ResumeRestore(archive Archive, previous RestoreReport) (RestoreReport, error) {
+	return t.ResumeRestoreContext(context.Background(), archive, previous)
+}
+
+func (t *Traggo) ResumeRestoreContext(ctx context.Context, archive Archive, previous RestoreReport) (RestoreReport, error) {
+	return t.restore(ctx, archive, true, previous)
+}
+
+func (t *Traggo) restore(ctx context.Context, archive Archive, force bool, previous RestoreReport) (RestoreReport, error) {
+	report := RestoreReport{Tags: previous.Tags, Dashboards: previous.Dashboards, Ids: map[int]int{}}
+	for old, id := range previous.Ids {
+		report.Ids[old] = id
+	}
+	if !force {
+		empty, err := t.isEmpty(ctx)
+		if err != nil {
+			return report, err
+		}
+		if !empty {
+			return report, ErrNotEmpty
+		}
+	}
+	// the cache holds the tasks of the account before the restore
+	defer t.Cache.Clear()
+
+	known, err := t.GetTagsContext(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, tag := range archive.Tags {
+		// Project and project are two tags
+		if known.Has(tag.Key) {
+			continue
+		}
+		_, err := t.CreateTagContext(ctx, tag.Key, tag.Color)
+		if err != nil {
+			return report, fmt.Errorf("tag '%s': %w", tag.Key, err)
+		}
+		report.Tags++
+	}
+
+	if archive.Settings != (UserSettingsData{}) {
+		_, err = t.SetSettingsContext(ctx, archive.Settings)
+		if err != nil {
+			return report, fmt.Errorf("settings: %w", err)
+		}
+	}
+
+	// oldest first so new ids keep the original order
+	spans := append(TimeSpanTaskList(nil), archive.TimeSpans...)
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].Start.Equal(spans[j].Start) {
+			return spans[i].Id < spans[j].Id
+		}
+		return spans[i].Start.Before(spans[j].Start)
+	})
+	for _, span := range spans {
+		if _, ok := report.Ids[span.Id]; ok {
+			continue
+		}
+		variables := struct {
+			Start time.Time `json:"start"`
+			End   time.Time `json:"end"`
+			Tags  []Tag     `json:"tags"`
+			Note  string    `json:"note"`
+		}{
+			Start: span.Start,
+			End:   span.End,
+			Tags:  span.Tags,
+			Note:  span.Note,
+		}
+		d, err := execute[createdTimeSpanData](ctx, t, qCreateTimeSpan, variables)
+		if err != nil {
+			return report, fmt.Errorf("time span %d: %w", span.Id, err)
+		}
+		report.Ids[span.Id] = d.Data.Id
+	}
+
+	for _, timer := range archive.Timers {
+		if _, ok := report.Ids[timer.Id]; ok {
+			continue
+		}
+		variables := struct {
+			Start time.Time `json:"start"`
+			Tags  []Tag     `json:"tags"`
+			Note  string    `json:"note"`
+		}{
+			Start: timer.Start,
+			Tags:  timer.Tags,
+			Note:  timer.Note,
+		}
+		d, err := execute[createTimeSpanData](ctx, t, qStartTimer, variables)
+		if err != nil {
+			return report, fmt.Errorf("timer %d: %w", timer.Id, err)
+		}
+		report.Ids[timer.Id] = d.Data.Id
+	}
+
+	for _, dashboard := range archive.Dashboards[min(previous.Dashboards, len(archive.Dashboards)):] {
+		_, err := t.CreateDashboardContext(ctx, dashboard)
+		if err != nil {
+			return report, err
+		}
+		report.Dashboards++
+	}
+	return report, nil
+}
@@ -0,0 +1,293 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+)
+
+// Cache is a local copy of the time spans, keyed by id, and of the current timers.
+// It is refreshed incrementally: the newest pages are downloaded until one
+// contains only known time spans. Mutations done through the session update
+// or invalidate it explicitly.
+// Time spans changed by another client far in the history are only seen
+// after a full refresh.
+type Cache struct {
+	Path        string        // cache file, not persisted if empty
+	TTL         time.Duration // cached data are used without asking Traggo during this delay
+	FullRefresh time.Duration // the whole history is downloaded again after this delay
+
+	mu     sync.Mutex
+	loaded bool
+	data   cacheData
+	sorted TimeSpanTaskList // time spans newest first, nil when outdated
+}
+
+type cacheData struct {
+	Spans         map[int]TimeSpanTask `json:"spans"`
+	Timers        []TimerTask          `json:"timers"`
+	Refreshed     time.Time            `json:"refreshed"` // zero when invalidated
+	FullRefreshed time.Time            `json:"fullRefreshed"`
+}
+
+func NewCache(path string, ttl, fullRefresh time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = config.DefaultCacheTTL
+	}
+	if fullRefresh <= 0 {
+		fullRefresh = config.DefaultCacheFullRefresh
+	}
+	return &Cache{Path: path, TTL: ttl, FullRefresh: fullRefresh}
+}
+
+// load reads the cache file once. An unreadable file is an empty cache.
+func (c *Cache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	if c.Path == "" {
+		return
+	}
+	d, err := os.ReadFile(c.Path)
+	if err != nil {
+		return
+	}
+	var data cacheData
+	if json.Unmarshal(d, &data) == nil {
+		c.data = data
+	}
+}
+
+// save writes the cache file. If it fails, the file is removed so
+// outdated data are never used again.
+func (c *Cache) save() {
+	c.sorted = nil
+	if c.Path == "" {
+		return
+	}
+	err := os.MkdirAll(filepath.Dir(c.Path), 0o770)
+	if err == nil {
+		var d []byte
+		d, err = json.Marshal(c.data)
+		if err == nil {
+			err = os.WriteFile(c.Path, d, 0o600)
+		}
+	}
+	if err != nil {
+		os.Remove(c.Path)
+	}
+}
+
+// Invalidate makes the next read ask Traggo for new tasks
+func (c *Cache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	if c.data.Spans == nil {
+		// never refreshed, nothing to invalidate
+		return
+	}
+	c.data.Refreshed = time.Time{}
+	c.save()
+}
+
+// Clear forgets everything, the next read downloads the whole history
+func (c *Cache) Clear() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loaded = true
+	c.data = cacheData{}
+	c.sorted = nil
+	if c.Path == "" {
+		return nil
+	}
+	err := os.Remove(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// put stores time spans returned by a mutation. Timers are invalidated
+// as a time span may come from a stopped timer.
+func (c *Cache) put(spans ...TimeSpanTask) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	if c.data.Spans == nil {
+		// never refreshed, nothing to update
+		return
+	}
+	for _, span := range spans {
+		c.data.Spans[span.Id] = span
+	}
+	c.data.Refreshed = time.Time{}
+	c.save()
+}
+
+// forget removes deleted time spans
+func (c *Cache) forget(ids ...int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	for _, id := range ids {
+		delete(c.data.Spans, id)
+	}
+	c.data.Refreshed = time.Time{}
+	c.save()
+}
+
+// known returns the cached tasks among ids, keyed by id, without refresh
+func (c *Cache) known(ids ...int) map[int]TimeSpanTask {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	found := map[int]TimeSpanTask{}
+	for _, id := range ids {
+		if span, ok := c.data.Spans[id]; ok {
+			found[id] = span
+		}
+	}
+	for _, timer := range c.data.Timers {
+		if slices.Contains(ids, timer.Id) {
+			found[timer.Id] = asTimeSpan(timer)
+		}
+	}
+	return found
+}
+
+// refreshCache updates the cache from Traggo if it is outdated.
+// The caller must hold t.Cache.mu.
+func (t *Traggo) refreshCache(ctx context.Context) error {
+	c := t.Cache
+	c.load()
+	now := TimeNow()
+	if c.data.Spans != nil && !c.data.Refreshed.IsZero() && now.Sub(c.data.Refreshed) < c.TTL {
+		return nil
+	}
+
+	timers, err := t.ListCurrentTasksContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	full := c.data.Spans == nil || now.Sub(c.data.FullRefreshed) >= c.FullRefresh
+	spans := c.data.Spans
+	if full {
+		spans = map[int]TimeSpanTask{}
+	}
+	err = t.eachTimeSpansPage(ctx, func(page TimeSpans) bool {
+		unknown := false
+		for _, span := range page.TimeSpans {
+			if _, ok := spans[span.Id]; !ok {
+				unknown = true
+			}
+			spans[span.Id] = span
+		}
+		return full || unknown
+	})
+	if err != nil {
+		return err
+	}
+
+	c.data.Spans = spans
+	c.data.Timers = timers.Timers
+	c.data.Refreshed = now
+	if full {
+		c.data.FullRefreshed = now
+	}
+	c.save()
+	return nil
+}
+
+// AllTasks returns current timers and time spans, newest first.
+// With a cache, only new time spans are downloaded.
+func (t *Traggo) AllTasks() (TimersData, TimeSpanTaskList, error) {
+	return t.AllTasksContext(context.Background())
+}
+
+func (t *Traggo) AllTasksContext(ctx context.Context) (TimersData, TimeSpanTaskList, error) {
+	if t.Cache == nil {
+		timers, err := t.ListCurrentTasksContext(ctx)
+		if err != nil {
+			return TimersData{}, nil, err
+		}
+		spans, err := t.ListCompleteTasksContext(ctx)
+		if err != nil {
+			return TimersData{}, nil, err
+		}
+		return timers, spans, nil
+	}
+
+	c := t.Cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := t.refreshCache(ctx)
+	if err != nil {
+		return TimersData{}, nil, err
+	}
+	if c.sorted == nil {
+		c.sorted = make(TimeSpanTaskList, 0, len(c.data.Spans))
+		for _, span := range c.data.Spans {
+			c.sorted = append(c.sorted, span)
+		}
+		sort.Slice(c.sorted, func(i, j int) bool {
+			if c.sorted[i].Start.Equal(c.sorted[j].Start) {
+				return c.sorted[i].Id > c.sorted[j].Id
+			}
+			return c.sorted[i].Start.After(c.sorted[j].Start)
+		})
+	}
+	timers := TimersData{Timers: append([]TimerTask(nil), c.data.Timers...)}
+	return timers, append(TimeSpanTaskList(nil), c.sorted...), nil
+}
+
+// cachedTask looks for id in the cache. A missing id triggers a refresh,
+// in case the task is newer than the cached data.
+func (t *Traggo) cachedTask(ctx context.Context, id int) (GenericTask, error) {
+	c := t.Cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			c.data.Refreshed = time.Time{}
+		}
+		err := t.refreshCache(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, timer := range c.data.Timers {
+			if timer.Id == id {
+				return timer, nil
+			}
+		}
+		if span, ok := c.data.Spans[id]; ok {
+			return span, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,220 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+)
+
+var (
+	// ErrEmptyResponse is returned when the server answers without "data" nor "errors"
+	ErrEmptyResponse = errors.New("empty response from server")
+	// ErrUnreachable is returned when the request cannot have reached Traggo
+	// (DNS or connection failure, or Traggo unavailable behind a reverse proxy).
+	// Timeouts are not, as Traggo may have received the request.
+	ErrUnreachable = errors.New("traggo is unreachable")
+)
+
+// Errors is the "errors" array of a GraphQL response.
+// A response containing at least one error is returned as an Errors value.
+type Errors []Error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+		if len(err.Path) > 0 {
+			path := make([]string, len(err.Path))
+			for j, p := range err.Path {
+				path[j] = fmt.Sprint(p)
+			}
+			messages[i] = fmt.Sprintf("%s (%s)", err.Message, strings.Join(path, "."))
+		}
+	}
+	return fmt.Sprintf("graphql: %s", strings.Join(messages, "; "))
+}
+
+// StatusError is returned when Traggo, or a proxy in front of it, answers
+// with an HTTP status other than 200 OK
+type StatusError struct {
+	Command string
+	Code    int
+	Status  string
+	Body    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("command '%s' failed. '%s': %s", e.Command, e.Status, e.Body)
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors Errors          `json:"errors"`
+}
+
+// isMutation tells if the GraphQL document modifies data on server side
+func isMutation(document string) bool {
+	return strings.HasPrefix(strings.TrimSpace(document), "mutation")
+}
+
+// Request sends the operation to Traggo and decodes the "data" member
+// of the response into model (if not nil).
+func (t *Traggo) Request(op Operation, model any) error {
+	return t.RequestContext(context.Background(), op, model)
+}
+
+// RequestContext is Request with a context.
+// Each attempt is limited by Traggo.Timeout. Queries failing because of
+// the network or a server error are retried up to Traggo.Retries times,
+// mutations are sent only once.
+func (t *Traggo) RequestContext(ctx context.Context, op Operation, model any) error {
+	body, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+
+	attempts := 1
+	if !isMutation(op.Query) && t.Retries > 0 {
+		attempts += t.Retries
+	}
+	backoff := t.Backoff
+	if backoff <= 0 {
+		backoff = config.DefaultBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		retryable, err := t.send(ctx, op.OperationName, body, model)
+		if err == nil || !retryable || attempt >= attempts {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// undelivered tells if err prevented the request from being sent, so a
+// mutation can be queued without risk of being applied twice
+func undelivered(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// rejected tells if err is an answer of Traggo refusing the request, a
+// GraphQL error or a 4xx status, so the request has not been applied
+func rejected(err error) bool {
+	var gqlErr Errors
+	if errors.As(err, &gqlErr) {
+		return true
+	}
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Code >= 400 && statusErr.Code < 500
+}
+
+// send does one attempt of the request. It reports if the failure
+// is worth a retry.
+func (t *Traggo) send(ctx context.Context, command string, body []byte, model any) (bool, error) {
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultTimeout
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, "POST", t.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	if t.Token != "" {
+		req.Header.Add("Cookie", fmt.Sprintf("traggo=%s", t.Token))
+	}
+
+	client := t.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		// nothing to retry if the caller gave up
+		if ctx.Err() != nil {
+			return false, fmt.Errorf("command '%s' failed: %w", command, err)
+		}
+		if !undelivered(err) {
+			// Traggo may have received the request, a timeout for example
+			return true, fmt.Errorf("command '%s' failed: %w", command, err)
+		}
+		return true, fmt.Errorf("command '%s' failed: %w: %w", command, ErrUnreachable, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		c, _ := io.ReadAll(res.Body)
+		err := &StatusError{Command: command, Code: res.StatusCode, Status: res.Status, Body: strings.TrimSpace(string(c))}
+		switch res.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable:
+			return true, fmt.Errorf("%w: %w", ErrUnreachable, err)
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusGatewayTimeout:
+			// Traggo may have received the request behind a gateway timeout
+			return true, err
+		}
+		return false, err
+	}
+
+	var r response
+	err = json.NewDecoder(res.Body).Decode(&r)
+	if err != nil {
+		return false, fmt.Errorf("command '%s': unable to decode response: %w", command, err)
+	}
+	if len(r.Errors) > 0 {
+		return false, r.Errors
+	}
+	if len(r.Data) == 0 || string(r.Data) == "null" {
+		return false, ErrEmptyResponse
+	}
+	if model == nil {
+		return false, nil
+	}
+	return false, json.Unmarshal(r.Data, model)
+}
+
+// execute runs the registered query with the provided variables
+// and returns its decoded "data" member.
+func execute[T any](ctx context.Context, t *Traggo, name string, variables any) (T, error) {
+	var d T
+	q, ok := queries[name]
+	if !ok {
+		return d, fmt.Errorf("unknown query '%s'", name)
+	}
+	op := Operation{
+		OperationName: q.OperationName,
+		Variables:     variables,
+		Query:         q.Document,
+	}
+	err := t.RequestContext(ctx, op, &d)
+	if isMutation(q.Document) {
+		// timers may have changed, even on error
+		t.Status.Invalidate()
+	}
+	return d, err
+}
@@ -0,0 +1,169 @@
+package session
+
+import (
+	"context"
+	"fmt"
+)
+
+// DateRange is a static date or a relative one like "now-7d"
+type DateRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// NamedDateRange is a date range saved in a dashboard
+type NamedDateRange struct {
+	Id       int       `json:"id"`
+	Name     string    `json:"name"`
+	Editable bool      `json:"editable"`
+	Range    DateRange `json:"range"`
+}
+
+// StatsSelection tells what a dashboard entry displays. The period is either
+// Range or the named range RangeId of the dashboard.
+type StatsSelection struct {
+	Interval    string     `json:"interval"`
+	Tags        []string   `json:"tags"`
+	ExcludeTags []Tag      `json:"excludeTags"`
+	IncludeTags []Tag      `json:"includeTags"`
+	Range       *DateRange `json:"range"`
+	RangeId     *int       `json:"rangeId"`
+}
+
+// DashboardEntryPos is the place of an entry in the dashboard grid
+type DashboardEntryPos struct {
+	X    int `json:"x"`
+	Y    int `json:"y"`
+	W    int `json:"w"`
+	H    int `json:"h"`
+	MinW int `json:"minW"`
+	MinH int `json:"minH"`
+}
+
+type DashboardEntryPositions struct {
+	Desktop DashboardEntryPos `json:"desktop"`
+	Mobile  DashboardEntryPos `json:"mobile"`
+}
+
+// DashboardEntry is a chart or a table of a dashboard
+type DashboardEntry struct {
+	Id             int                     `json:"id"`
+	Title          string                  `json:"title"`
+	Total          bool                    `json:"total"`
+	EntryType      string                  `json:"entryType"`
+	Pos            DashboardEntryPositions `json:"pos"`
+	StatsSelection StatsSelection          `json:"statsSelection"`
+}
+
+type Dashboard struct {
+	Id     int              `json:"id"`
+	Name   string           `json:"name"`
+	Items  []DashboardEntry `json:"items"`
+	Ranges []NamedDateRange `json:"ranges"`
+}
+
+type dashboardsData struct {
+	Dashboards []Dashboard `json:"dashboards"`
+}
+
+type createDashboardData struct {
+	Dashboard Dashboard `json:"createDashboard"`
+}
+
+type addDashboardRangeData struct {
+	Range NamedDateRange `json:"addDashboardRange"`
+}
+
+type addDashboardEntryData struct {
+	Entry DashboardEntry `json:"addDashboardEntry"`
+}
+
+func (t *Traggo) GetDashboards() ([]Dashboard, error) {
+	return t.GetDashboardsContext(context.Background())
+}
+
+func (t *Traggo) GetDashboardsContext(ctx context.Context) ([]Dashboard, error) {
+	d, err := execute[dashboardsData](ctx, t, qDashboards, nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.Dashboards, nil
+}
+
+// CreateDashboard creates dashboard with its ranges and entries.
+// Ids of dashboard are ignored, the returned dashboard has the new ones.
+func (t *Traggo) CreateDashboard(dashboard Dashboard) (Dashboard, error) {
+	return t.CreateDashboardContext(context.Background(), dashboard)
+}
+
+func (t *Traggo) CreateDashboardContext(ctx context.Context, dashboard Dashboard) (Dashboard, error) {
+	variables := struct {
+		Name string `json:"name"`
+	}{
+		Name: dashboard.Name,
+	}
+	d, err := execute[createDashboardData](ctx, t, qCreateDashboard, variables)
+	if err != nil {
+		return Dashboard{}, err
+	}
+	created := d.Dashboard
+
+	// entries refer to the ranges of the dashboard by id
+	rangeIds := map[int]int{}
+	for _, r := range dashboard.Ranges {
+		variables := struct {
+			DashboardId int `json:"dashboardId"`
+			Range       struct {
+				Name     string    `json:"name"`
+				Editable bool      `json:"editable"`
+				Range    DateRange `json:"range"`
+			} `json:"range"`
+		}{
+			DashboardId: created.Id,
+		}
+		variables.Range.Name = r.Name
+		variables.Range.Editable = r.Editable
+		variables.Range.Range = r.Range
+		d, err := execute[addDashboardRangeData](ctx, t, qAddDashRange, variables)
+		if err != nil {
+			return created, fmt.Errorf("range '%s' of dashboard '%s': %w", r.Name, dashboard.Name, err)
+		}
+		rangeIds[r.Id] = d.Range.Id
+		r.Id = d.Range.Id
+		created.Ranges = append(created.Ranges, r)
+	}
+
+	for _, e := range dashboard.Items {
+		stats := e.StatsSelection
+		if stats.RangeId != nil {
+			id, ok := rangeIds[*stats.RangeId]
+			if !ok {
+				return created, fmt.Errorf("entry '%s' of dashboard '%s' refers to unknown range %d", e.Title, dashboard.Name, *stats.RangeId)
+			}
+			stats.RangeId = &id
+		}
+		variables := struct {
+			DashboardId int                     `json:"dashboardId"`
+			EntryType   string                  `json:"entryType"`
+			Title       string                  `json:"title"`
+			Total       bool                    `json:"total"`
+			Stats       StatsSelection          `json:"stats"`
+			Pos         DashboardEntryPositions `json:"pos"`
+		}{
+			DashboardId: created.Id,
+			EntryType:   e.EntryType,
+			Title:       e.Title,
+			Total:       e.Total,
+			Stats:       stats,
+			Pos:         e.Pos,
+		}
+		d, err := execute[addDashboardEntryData](ctx, t, qAddDashEntry, variables)
+		if err != nil {
+			return created, fmt.Errorf("entry '%s' of dashboard '%s': %w", e.Title, dashboard.Name, err)
+		}
+		e.Id = d.Entry.Id
+		e.StatsSelection = stats
+		created.Items = append(created.Items, e)
+	}
+	return created, nil
+}
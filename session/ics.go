@@ -0,0 +1,106 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const icsDateTime = "20060102T150405Z"
+
+// ICSOptions shapes the calendar built by ToICS
+type ICSOptions struct {
+	Host        string   // domain of the event UIDs, the Traggo host
+	SummaryTags []string // tag names whose values make the event summary, all tags if empty
+}
+
+// icsEscape escapes a TEXT value (RFC 5545 section 3.3.11)
+func icsEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// icsFold folds a content line at 75 octets (RFC 5545 section 3.1),
+// without cutting UTF-8 characters
+func icsFold(line string) string {
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space counts in the next line
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// icsSummary returns the values of the summary tags, or all tags
+func icsSummary(task TimerTask, summaryTags []string) string {
+	var parts []string
+	if len(summaryTags) == 0 {
+		parts = task.ExportTags()
+	} else {
+		for _, v := range tagValues(task, summaryTags) {
+			if v != "" {
+				parts = append(parts, v)
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return "Traggo"
+	}
+	return strings.Join(parts, " / ")
+}
+
+// ToICS converts time spans to an iCalendar with one VEVENT each.
+// Running timers end now and are marked as tentative.
+// Each UID is derived from the Traggo id, so importing again updates events.
+func ToICS(timers TimersData, spans TimeSpanTaskList, opts ICSOptions) string {
+	var b strings.Builder
+	now := TimeNow().UTC()
+	event := func(task TimerTask, end time.Time, status string) {
+		lines := []string{
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:traggo-%d@%s", task.Id, opts.Host),
+			"DTSTAMP:" + now.Format(icsDateTime),
+			"DTSTART:" + task.Start.UTC().Format(icsDateTime),
+			"DTEND:" + end.UTC().Format(icsDateTime),
+			"SUMMARY:" + icsEscape(icsSummary(task, opts.SummaryTags)),
+		}
+		if task.Note != "" {
+			lines = append(lines, "DESCRIPTION:"+icsEscape(task.Note))
+		}
+		if tags := task.ExportTags(); len(tags) > 0 {
+			escaped := make([]string, len(tags))
+			for i, tag := range tags {
+				escaped[i] = icsEscape(tag)
+			}
+			lines = append(lines, "CATEGORIES:"+strings.Join(escaped, ","))
+		}
+		lines = append(lines, "STATUS:"+status, "END:VEVENT")
+		for _, l := range lines {
+			b.WriteString(icsFold(l))
+		}
+	}
+
+	b.WriteString(icsFold("BEGIN:VCALENDAR"))
+	b.WriteString(icsFold("VERSION:2.0"))
+	b.WriteString(icsFold("PRODID:-//traggo_cli//EN"))
+	b.WriteString(icsFold("CALSCALE:GREGORIAN"))
+	for _, task := range timers.Timers {
+		event(task, now, "TENTATIVE")
+	}
+	for _, task := range spans {
+		event(task.TimerTask, task.End, "CONFIRMED")
+	}
+	b.WriteString(icsFold("END:VCALENDAR"))
+	return b.String()
+}
@@ -0,0 +1,396 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats read by ParseImport
+const (
+	ImportCSV      = "csv"
+	ImportJSON     = "json"
+	ImportToggl    = "toggl"
+	ImportClockify = "clockify"
+)
+
+// ImportMapping tells which columns (or JSON keys) hold the time span fields.
+// Start and End hold a date and time. When empty, StartDate/StartTime and
+// EndDate/EndTime are joined instead.
+type ImportMapping struct {
+	Start     string
+	End       string
+	StartDate string
+	StartTime string
+	EndDate   string
+	EndTime   string
+	Note      string
+	Tags      string            // list of TagName:TagValue separated by ';' or ','
+	ListTag   string            // tag name for the values of Tags without ':'
+	Columns   map[string]string // column name => tag name, the column value being the tag value
+}
+
+// DefaultImportMapping reads files written by 'traggo_cli list --output csv|json'
+func DefaultImportMapping() ImportMapping {
+	return ImportMapping{Start: "start", End: "end", Note: "note", Tags: "tags", Columns: map[string]string{}}
+}
+
+// TogglMapping reads Toggl Track detailed CSV exports
+func TogglMapping() ImportMapping {
+	return ImportMapping{
+		StartDate: "Start date",
+		StartTime: "Start time",
+		EndDate:   "End date",
+		EndTime:   "End time",
+		Note:      "Description",
+		Tags:      "Tags",
+		ListTag:   "tag",
+		Columns:   map[string]string{"Project": "project", "Client": "client", "Task": "task"},
+	}
+}
+
+// ClockifyMapping reads Clockify detailed CSV reports
+func ClockifyMapping() ImportMapping {
+	return ImportMapping{
+		StartDate: "Start Date",
+		StartTime: "Start Time",
+		EndDate:   "End Date",
+		EndTime:   "End Time",
+		Note:      "Description",
+		Tags:      "Tags",
+		ListTag:   "tag",
+		Columns:   map[string]string{"Project": "project", "Client": "client", "Task": "task"},
+	}
+}
+
+// over adds the columns mapped to tags in m to the preset mapping
+func (m ImportMapping) over(preset ImportMapping) ImportMapping {
+	for column, tagName := range m.Columns {
+		preset.Columns[column] = tagName
+	}
+	return preset
+}
+
+// ImportEntry is one time span read from an import file, or a timer if its
+// end is zero
+type ImportEntry struct {
+	Line int // line in CSV files, position in JSON arrays (from 1)
+	Span TimeSpanTask
+	Err  error // the line cannot be converted to a time span
+}
+
+// importLayouts are the date formats accepted, in local time unless a zone is given
+var importLayouts = []string{
+	time.RFC3339,
+	time.DateTime,
+	"2006-01-02 15:04",
+	"2006-01-02 03:04:05 PM",
+	"2006-01-02 03:04 PM",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+	"01/02/2006 03:04:05 PM",
+	"01/02/2006 03:04 PM",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+}
+
+func parseImportTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range importLayouts {
+		d, err := time.ParseInLocation(layout, s, time.Local)
+		if err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format '%s'", s)
+}
+
+// dateTime returns the value of column, or the date and time columns joined
+func dateTime(row map[string]string, column, dateColumn, timeColumn string) (time.Time, error) {
+	if column != "" {
+		return parseImportTime(row[column])
+	}
+	return parseImportTime(row[dateColumn] + " " + row[timeColumn])
+}
+
+// running tells if row has no end
+func (m ImportMapping) running(row map[string]string) bool {
+	if m.End != "" {
+		return strings.TrimSpace(row[m.End]) == ""
+	}
+	return strings.TrimSpace(row[m.EndDate]+row[m.EndTime]) == ""
+}
+
+// toSpan converts a row to a time span, without end for a running timer
+func (m ImportMapping) toSpan(row map[string]string) (TimeSpanTask, error) {
+	var span TimeSpanTask
+	var err error
+	span.Start, err = dateTime(row, m.Start, m.StartDate, m.StartTime)
+	if err != nil {
+		return span, fmt.Errorf("start: %w", err)
+	}
+	// rows without end, as the running timers saved by rm, are timers
+	if !m.running(row) {
+		span.End, err = dateTime(row, m.End, m.EndDate, m.EndTime)
+		if err != nil {
+			return span, fmt.Errorf("end: %w", err)
+		}
+		if !span.End.After(span.Start) {
+			return span, fmt.Errorf("end %s is not after start %s", span.End.Format(time.DateTime), span.Start.Format(time.DateTime))
+		}
+	}
+	span.Note = row[m.Note]
+
+	if m.Tags != "" {
+		for _, tag := range strings.FieldsFunc(row[m.Tags], func(r rune) bool { return r == ';' || r == ',' }) {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(tag, ":")
+			if !ok {
+				if m.ListTag == "" {
+					return span, fmt.Errorf("invalid tag '%s', expected TagName:TagValue", tag)
+				}
+				key, value = m.ListTag, tag
+			}
+			span.Tags = append(span.Tags, Tag{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+		}
+	}
+	columns := make([]string, 0, len(m.Columns))
+	for column := range m.Columns {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		if value := strings.TrimSpace(row[column]); value != "" {
+			span.Tags = append(span.Tags, Tag{Key: m.Columns[column], Value: value})
+		}
+	}
+	return span, nil
+}
+
+// readCSVRows returns the rows of a CSV file with a header line, keyed by column name
+func readCSVRows(r io.Reader) ([]map[string]string, error) {
+	// Excel and Clockify may add a byte order mark
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\ufeff" {
+		br.Discard(3)
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		for i, value := range record {
+			if i < len(header) {
+				row[strings.TrimSpace(header[i])] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readJSONRows returns the objects of a JSON array. Values are converted to
+// strings, arrays being joined with ';'.
+func readJSONRows(r io.Reader) ([]map[string]string, error) {
+	var objects []map[string]any
+	err := json.NewDecoder(r).Decode(&objects)
+	if err != nil {
+		return nil, err
+	}
+	var str func(v any) string
+	str = func(v any) string {
+		switch v := v.(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case []any:
+			s := make([]string, len(v))
+			for i, e := range v {
+				s[i] = str(e)
+			}
+			return strings.Join(s, ";")
+		}
+		return fmt.Sprint(v)
+	}
+	rows := make([]map[string]string, len(objects))
+	for i, o := range objects {
+		rows[i] = map[string]string{}
+		for k, v := range o {
+			rows[i][k] = str(v)
+		}
+	}
+	return rows, nil
+}
+
+// ParseImport reads time spans from r. Lines which cannot be converted are
+// returned with their error, so the whole file is checked at once.
+func ParseImport(r io.Reader, format string, mapping ImportMapping) ([]ImportEntry, error) {
+	var rows []map[string]string
+	var err error
+	// first data line of a CSV file is the second line of the file
+	offset := 2
+	switch format {
+	case ImportCSV:
+		rows, err = readCSVRows(r)
+	case ImportToggl:
+		rows, err = readCSVRows(r)
+		mapping = mapping.over(TogglMapping())
+	case ImportClockify:
+		rows, err = readCSVRows(r)
+		mapping = mapping.over(ClockifyMapping())
+	case ImportJSON:
+		rows, err = readJSONRows(r)
+		offset = 1
+	default:
+		return nil, fmt.Errorf("unknown import format '%s', expected csv, json, toggl or clockify", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s file: %w", format, err)
+	}
+
+	entries := make([]ImportEntry, len(rows))
+	for i, row := range rows {
+		span, err := mapping.toSpan(row)
+		entries[i] = ImportEntry{Line: i + offset, Span: span, Err: err}
+	}
+	return entries, nil
+}
+
+// ImportReport describes what happened to the imported time spans
+type ImportReport struct {
+	Created    TimeSpanTaskList // or to be created with a dry run, timers have no end
+	Queued     TimeSpanTaskList // Traggo became unreachable, see Sync
+	Duplicates []ImportEntry    // the same time span, or timer, already exists
+	Running    []ImportEntry    // entries without end, skipped as timers are not started
+	Failures   []ImportEntry
+}
+
+// spanKey identifies a time span for duplicate detection: parallel time
+// spans are valid, they differ by their tags or note
+func spanKey(span TimeSpanTask) string {
+	tags := span.ExportTags()
+	sort.Strings(tags)
+	return fmt.Sprintf("%d %d %q %q", span.Start.Unix(), span.End.Unix(), tags, span.Note)
+}
+
+// Import creates the time spans of entries. Entries without end are timers,
+// started only with startTimers. A time span with the same start, end, tags
+// and note as an existing one (or a previous entry), or a timer with the same
+// start, tags and note as a running one, is a duplicate and skipped.
+// Nothing is created with dryRun.
+func (t *Traggo) Import(entries []ImportEntry, dryRun, startTimers bool) (ImportReport, error) {
+	return t.ImportContext(context.Background(), entries, dryRun, startTimers)
+}
+
+func (t *Traggo) ImportContext(ctx context.Context, entries []ImportEntry, dryRun, startTimers bool) (ImportReport, error) {
+	var report ImportReport
+	var valid []ImportEntry
+	var first, last time.Time
+	hasTimers := false
+	for _, e := range entries {
+		if e.Err != nil {
+			report.Failures = append(report.Failures, e)
+			continue
+		}
+		if e.Span.End.IsZero() && !startTimers {
+			report.Running = append(report.Running, e)
+			continue
+		}
+		valid = append(valid, e)
+		if first.IsZero() || e.Span.Start.Before(first) {
+			first = e.Span.Start
+		}
+		if e.Span.End.IsZero() {
+			hasTimers = true
+			last = maxTime(last, e.Span.Start)
+		} else {
+			last = maxTime(last, e.Span.End)
+		}
+	}
+	if len(valid) == 0 {
+		return report, nil
+	}
+
+	existing, err := t.ListBetweenDatesContext(ctx, first, last)
+	if err != nil {
+		return report, err
+	}
+	known := map[string]bool{}
+	for _, span := range existing {
+		known[spanKey(span)] = true
+	}
+	if hasTimers {
+		timers, err := t.ListCurrentTasksContext(ctx)
+		if err != nil {
+			return report, err
+		}
+		for _, timer := range timers.Timers {
+			known[spanKey(asTimeSpan(timer))] = true
+		}
+	}
+
+	for _, e := range valid {
+		key := spanKey(e.Span)
+		if known[key] {
+			report.Duplicates = append(report.Duplicates, e)
+			continue
+		}
+		known[key] = true
+		if dryRun {
+			report.Created = append(report.Created, e.Span)
+			continue
+		}
+		var span TimeSpanTask
+		if e.Span.End.IsZero() {
+			span, err = t.importTimer(ctx, e.Span)
+		} else {
+			span, err = t.CreateTimeSpanContext(ctx, e.Span)
+		}
+		switch {
+		case errors.Is(err, ErrQueued):
+			report.Queued = append(report.Queued, span)
+		case err != nil:
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			e.Err = err
+			report.Failures = append(report.Failures, e)
+		default:
+			report.Created = append(report.Created, span)
+		}
+	}
+	return report, nil
+}
+
+// importTimer starts the timer of an import entry
+func (t *Traggo) importTimer(ctx context.Context, task TimeSpanTask) (TimeSpanTask, error) {
+	timer, err := t.startTimer(ctx, task.Tags, task.Note, task.Start)
+	if err != nil {
+		return asTimeSpan(timer), err
+	}
+	after := asTimeSpan(timer)
+	t.Journal.record(OpStart, JournalChange{After: &after})
+	return after, nil
+}
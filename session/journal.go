@@ -0,0 +1,387 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+)
+
+// Operations recorded in the journal
+const (
+	OpStart    = "start"
+	OpCreate   = "create"
+	OpStop     = "stop"
+	OpUpdate   = "update"
+	OpContinue = "continue"
+	OpDelete   = "delete"
+	OpSwitch   = "switch"
+)
+
+// ErrNothingToUndo is returned by Undo when the journal has no operation to revert
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// JournalChange is the state of one task before and after an operation.
+// Running timers are stored with a zero End.
+type JournalChange struct {
+	Before *TimeSpanTask `json:"before,omitempty"` // nil for a created task
+	After  *TimeSpanTask `json:"after,omitempty"`  // nil for a deleted task
+}
+
+// JournalEntry is one operation, which may change several tasks
+type JournalEntry struct {
+	Op      string          `json:"op"`
+	Time    time.Time       `json:"time"`
+	Changes []JournalChange `json:"changes"`
+	Undone  bool            `json:"undone,omitempty"`
+}
+
+func (e JournalEntry) String() string {
+	if len(e.Changes) != 1 {
+		return fmt.Sprintf("%s of %d tasks", e.Op, len(e.Changes))
+	}
+	task := e.Changes[0].After
+	if task == nil {
+		task = e.Changes[0].Before
+	}
+	s := fmt.Sprintf("%s of task %d [%s] started %s", e.Op, task.Id, strings.Join(task.ExportTags(), ","), task.Start.Local().Format(time.DateTime))
+	if task.Note != "" {
+		s += fmt.Sprintf(" '%s'", task.Note)
+	}
+	return s
+}
+
+// Journal keeps the last mutations done through the session so they can
+// be reverted with Undo and replayed with Redo.
+// Undone operations are forgotten as soon as a new one is recorded.
+type Journal struct {
+	Path string
+	Size int // maximum number of operations kept
+
+	mu      sync.Mutex
+	loaded  bool
+	entries []JournalEntry // oldest first, undone ones at the end
+}
+
+func NewJournal(path string, size int) *Journal {
+	if size <= 0 {
+		size = config.DefaultJournalSize
+	}
+	return &Journal{Path: path, Size: size}
+}
+
+// load reads the journal file once. An unreadable file is an empty journal.
+func (j *Journal) load() {
+	if j.loaded {
+		return
+	}
+	j.loaded = true
+	if j.Path == "" {
+		return
+	}
+	d, err := os.ReadFile(j.Path)
+	if err != nil {
+		return
+	}
+	var entries []JournalEntry
+	if json.Unmarshal(d, &entries) == nil {
+		j.entries = entries
+	}
+}
+
+func (j *Journal) save() error {
+	if j.Path == "" {
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(j.Path), 0o770)
+	if err != nil {
+		return err
+	}
+	d, err := json.Marshal(j.entries)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(j.Path, d, 0o600)
+}
+
+// Entries returns the recorded operations, oldest first
+func (j *Journal) Entries() []JournalEntry {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.load()
+	return append([]JournalEntry(nil), j.entries...)
+}
+
+// record adds an operation. Failing to save the journal does not fail the
+// mutation, which is already done.
+func (j *Journal) record(op string, changes ...JournalChange) {
+	if j == nil || len(changes) == 0 {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.load()
+	// undone operations cannot be redone anymore
+	for len(j.entries) > 0 && j.entries[len(j.entries)-1].Undone {
+		j.entries = j.entries[:len(j.entries)-1]
+	}
+	j.entries = append(j.entries, JournalEntry{Op: op, Time: TimeNow(), Changes: changes})
+	if len(j.entries) > j.Size {
+		j.entries = j.entries[len(j.entries)-j.Size:]
+	}
+	j.save()
+}
+
+// done returns the number of operations which are not undone
+func (j *Journal) done() int {
+	n := len(j.entries)
+	for n > 0 && j.entries[n-1].Undone {
+		n--
+	}
+	return n
+}
+
+// renumber replaces the id of a recreated task in all the operations
+func (j *Journal) renumber(oldId, newId int) {
+	for _, e := range j.entries {
+		for _, c := range e.Changes {
+			for _, task := range []*TimeSpanTask{c.Before, c.After} {
+				if task != nil && task.Id == oldId {
+					task.Id = newId
+				}
+			}
+		}
+	}
+}
+
+// asTimeSpan converts a task to the form stored in the journal
+func asTimeSpan(task GenericTask) TimeSpanTask {
+	switch task := task.(type) {
+	case TimerTask:
+		return TimeSpanTask{TimerTask: task}
+	case TimeSpanTask:
+		return task
+	}
+	return TimeSpanTask{}
+}
+
+// journalTasks returns the state of tasks before a mutation, keyed by id.
+// They are read from the cache, and searched only if they are missing from
+// it: UpdateTaskFrom and DeleteTasks avoid this when the state is known.
+// Tasks which cannot be found, Traggo being unreachable for example, are
+// missing from the result and not recorded.
+func (t *Traggo) journalTasks(ctx context.Context, ids ...int) map[int]TimeSpanTask {
+	if t.Journal == nil {
+		return nil
+	}
+	found := t.Cache.known(ids...)
+	if found == nil {
+		found = map[int]TimeSpanTask{}
+	}
+	for _, id := range ids {
+		if _, ok := found[id]; ok {
+			continue
+		}
+		task, err := t.SearchTaskContext(ctx, id)
+		if err != nil {
+			break
+		}
+		if task != nil {
+			found[id] = asTimeSpan(task)
+		}
+	}
+	return found
+}
+
+// updated returns the change of a task whose previous state is in before.
+// ok is false if the previous state is unknown.
+func updated(before map[int]TimeSpanTask, after TimeSpanTask) (JournalChange, bool) {
+	b, ok := before[after.Id]
+	if !ok {
+		return JournalChange{}, false
+	}
+	return JournalChange{Before: &b, After: &after}, true
+}
+
+// applyState changes a task from one state to the other: it is created if
+// from is nil, deleted if to is nil, updated otherwise.
+// The id of the created task is returned.
+// Mutations are sent directly, they are neither queued nor recorded.
+func (t *Traggo) applyState(ctx context.Context, from, to *TimeSpanTask) (int, error) {
+	switch {
+	case from == nil && to.End.IsZero():
+		variables := struct {
+			Start time.Time `json:"start"`
+			Tags  []Tag     `json:"tags"`
+			Note  string    `json:"note"`
+		}{
+			Start: to.Start,
+			Tags:  to.Tags,
+			Note:  to.Note,
+		}
+		d, err := execute[createTimeSpanData](ctx, t, qStartTimer, variables)
+		return d.Data.Id, err
+	case from == nil:
+		variables := struct {
+			Start time.Time `json:"start"`
+			End   time.Time `json:"end"`
+			Tags  []Tag     `json:"tags"`
+			Note  string    `json:"note"`
+		}{
+			Start: to.Start,
+			End:   to.End,
+			Tags:  to.Tags,
+			Note:  to.Note,
+		}
+		d, err := execute[createdTimeSpanData](ctx, t, qCreateTimeSpan, variables)
+		return d.Data.Id, err
+	case to == nil:
+		variables := struct {
+			Id int `json:"id"`
+		}{
+			Id: from.Id,
+		}
+		_, err := execute[removeTimeSpanData](ctx, t, qRemoveTimeSpan, variables)
+		return from.Id, err
+	}
+
+	tags := to.Tags
+	if tags == nil {
+		// tags are replaced, send an empty list to remove them
+		tags = []Tag{}
+	}
+	if to.End.IsZero() {
+		// without end, the time span runs again
+		variables := struct {
+			OldStart time.Time `json:"oldStart"`
+			Id       int       `json:"id"`
+			Start    time.Time `json:"start"`
+			Tags     []Tag     `json:"tags"`
+			Note     string    `json:"note"`
+		}{
+			OldStart: from.Start,
+			Id:       from.Id,
+			Start:    to.Start,
+			Tags:     tags,
+			Note:     to.Note,
+		}
+		_, err := execute[updateTimeSpanData](ctx, t, qUpdateTimer, variables)
+		return from.Id, err
+	}
+	variables := struct {
+		OldStart time.Time `json:"oldStart"`
+		Id       int       `json:"id"`
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end"`
+		Tags     []Tag     `json:"tags"`
+		Note     string    `json:"note"`
+	}{
+		OldStart: from.Start,
+		Id:       from.Id,
+		Start:    to.Start,
+		End:      to.End,
+		Tags:     tags,
+		Note:     to.Note,
+	}
+	_, err := execute[updateTimeSpanData](ctx, t, qUpdateTimeSpan, variables)
+	return from.Id, err
+}
+
+// cacheState updates the cache once a task changed from one state to the other
+func (t *Traggo) cacheState(from, to *TimeSpanTask) {
+	switch {
+	case to != nil && !to.End.IsZero():
+		t.Cache.put(*to)
+	case from != nil:
+		// deleted, or running again
+		t.Cache.forget(from.Id)
+	default:
+		t.Cache.Invalidate()
+	}
+}
+
+// Undo reverts the last n operations, newest first, and returns them.
+// The changes of an operation are reverted in reverse order.
+// Deleted tasks are recreated with a new id.
+func (t *Traggo) Undo(n int) ([]JournalEntry, error) {
+	return t.UndoContext(context.Background(), n)
+}
+
+func (t *Traggo) UndoContext(ctx context.Context, n int) ([]JournalEntry, error) {
+	return t.replay(ctx, n, true)
+}
+
+// Redo replays the last n undone operations, oldest first, and returns them
+func (t *Traggo) Redo(n int) ([]JournalEntry, error) {
+	return t.RedoContext(context.Background(), n)
+}
+
+func (t *Traggo) RedoContext(ctx context.Context, n int) ([]JournalEntry, error) {
+	return t.replay(ctx, n, false)
+}
+
+func (t *Traggo) replay(ctx context.Context, n int, undo bool) ([]JournalEntry, error) {
+	j := t.Journal
+	if j == nil {
+		return nil, errors.New("journal is disabled")
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.load()
+
+	var replayed []JournalEntry
+	for ; n > 0; n-- {
+		i := j.done()
+		if !undo {
+			// oldest undone operation
+			if i == len(j.entries) {
+				break
+			}
+		} else {
+			if i == 0 {
+				break
+			}
+			i--
+		}
+		e := &j.entries[i]
+		for k := range e.Changes {
+			c := e.Changes[k]
+			from, to := c.Before, c.After
+			if undo {
+				c = e.Changes[len(e.Changes)-1-k]
+				from, to = c.After, c.Before
+			}
+			id, err := t.applyState(ctx, from, to)
+			if err != nil {
+				err = fmt.Errorf("%s: %w", e, err)
+				// an operation partly reverted cannot be replayed safely
+				j.entries = append(j.entries[:i], j.entries[i+1:]...)
+				j.save()
+				return replayed, err
+			}
+			if from == nil && id != to.Id {
+				j.renumber(to.Id, id)
+			}
+			t.cacheState(from, to)
+		}
+		e.Undone = undo
+		replayed = append(replayed, *e)
+	}
+	if len(replayed) == 0 {
+		if undo {
+			return nil, ErrNothingToUndo
+		}
+		return nil, errors.New("nothing to redo")
+	}
+	return replayed, j.save()
+}
@@ -0,0 +1,265 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/kalidor/traggo_cli/config"
+)
+
+// Kinds of issues reported by Lint
+const (
+	IssueOverlap   = "overlap"    // two time spans overlap
+	IssueGap       = "gap"        // nothing tracked during working hours
+	IssueDuration  = "duration"   // a time span ends before or when it starts
+	IssueLongTimer = "long-timer" // a timer runs for too long, probably forgotten
+)
+
+// LintOptions tells what Lint reports
+type LintOptions struct {
+	WorkStart time.Duration // beginning of working hours, from midnight
+	WorkEnd   time.Duration // end of working hours, from midnight
+	Gap       time.Duration // shortest gap reported, none if 0
+	MaxTimer  time.Duration // longest timer not reported, none if 0
+}
+
+// LintFix is the change solving an issue
+type LintFix struct {
+	Description string
+	Before      TimeSpanTask // state of the task before the fix
+	Task        TimeSpanTask // new state of the task
+	Stop        bool         // the task is a timer to stop at Task.End
+}
+
+// LintIssue is a suspicious task, or pair of tasks
+type LintIssue struct {
+	Kind  string
+	Tasks []GenericTask
+	Start time.Time // period concerned by the issue
+	End   time.Time
+	Fix   *LintFix // nil if the issue has to be fixed by hand
+}
+
+type LintIssues []LintIssue
+
+// workDay returns the working hours of the day of d
+func (o LintOptions) workDay(d time.Time) (time.Time, time.Time) {
+	d = d.Local()
+	midnight := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, d.Location())
+	return midnight.Add(o.WorkStart), midnight.Add(o.WorkEnd)
+}
+
+// Lint looks for overlapping time spans, gaps longer than opts.Gap during
+// working hours between time spans of the same day, time spans without
+// positive duration and timers running for more than opts.MaxTimer.
+// Issues are sorted by start.
+func Lint(timers TimersData, spans TimeSpanTaskList, opts LintOptions) LintIssues {
+	var issues LintIssues
+	now := TimeNow()
+
+	sorted := append(TimeSpanTaskList(nil), spans...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+	var valid TimeSpanTaskList
+	for _, span := range sorted {
+		if span.End.After(span.Start) {
+			valid = append(valid, span)
+			continue
+		}
+		issue := LintIssue{Kind: IssueDuration, Tasks: []GenericTask{span}, Start: span.End, End: span.Start}
+		if span.End.Before(span.Start) {
+			fixed := span
+			fixed.OldStart, fixed.Start, fixed.End = span.Start, span.End, span.Start
+			issue.Fix = &LintFix{Description: "swap start and end", Before: span, Task: fixed}
+		}
+		issues = append(issues, issue)
+	}
+
+	for i, span := range valid {
+		// all the following spans starting before the end overlap. Only the
+		// first one, starting the earliest, proposes to end the span, as fixes
+		// are applied one after the other.
+		for k, next := range valid[i+1:] {
+			if !next.Start.Before(span.End) {
+				break
+			}
+			issue := LintIssue{Kind: IssueOverlap, Tasks: []GenericTask{span, next}, Start: next.Start, End: minTime(span.End, next.End)}
+			if k == 0 && span.Start.Before(next.Start) && !span.End.After(next.End) {
+				trimmed := span
+				trimmed.OldStart, trimmed.End = span.Start, next.Start
+				issue.Fix = &LintFix{Description: fmt.Sprintf("end task %d at %s", span.Id, next.Start.Local().Format(time.DateTime)), Before: span, Task: trimmed}
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	if opts.Gap > 0 {
+		// end of the latest span, as spans may be included in others
+		var previous *TimeSpanTask
+		for i := range valid {
+			span := valid[i]
+			if previous != nil && sameDay(previous.End, span.Start) && span.Start.After(previous.End) {
+				workStart, workEnd := opts.workDay(span.Start)
+				start, end := maxTime(previous.End, workStart), minTime(span.Start, workEnd)
+				if end.Sub(start) >= opts.Gap {
+					issues = append(issues, LintIssue{Kind: IssueGap, Tasks: []GenericTask{*previous, span}, Start: start, End: end})
+				}
+			}
+			if previous == nil || span.End.After(previous.End) {
+				previous = &valid[i]
+			}
+		}
+	}
+
+	if opts.MaxTimer > 0 {
+		for _, timer := range timers.Timers {
+			if now.Sub(timer.Start) <= opts.MaxTimer {
+				continue
+			}
+			// stopped at the end of its working day, or after MaxTimer
+			_, end := opts.workDay(timer.Start)
+			if !end.After(timer.Start) {
+				end = timer.Start.Add(opts.MaxTimer)
+			}
+			end = minTime(end, now)
+			stopped := TimeSpanTask{TimerTask: timer, End: end}
+			issues = append(issues, LintIssue{
+				Kind:  IssueLongTimer,
+				Tasks: []GenericTask{timer},
+				Start: timer.Start,
+				End:   now,
+				Fix:   &LintFix{Description: fmt.Sprintf("stop at %s", end.Local().Format(time.DateTime)), Before: asTimeSpan(timer), Task: stopped, Stop: true},
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Start.Before(issues[j].Start)
+	})
+	return issues
+}
+
+func sameDay(a, b time.Time) bool {
+	a, b = a.Local(), b.Local()
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// LintBetween runs Lint on the time spans between startDate and endDate and
+// on the current timers
+func (t *Traggo) LintBetween(startDate, endDate time.Time, opts LintOptions) (LintIssues, error) {
+	return t.LintBetweenContext(context.Background(), startDate, endDate, opts)
+}
+
+func (t *Traggo) LintBetweenContext(ctx context.Context, startDate, endDate time.Time, opts LintOptions) (LintIssues, error) {
+	spans, err := t.ListBetweenDatesContext(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	timers, err := t.ListCurrentTasksContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Lint(timers, spans, opts), nil
+}
+
+// ApplyLintFix changes the task of fix, which is recorded in the journal
+func (t *Traggo) ApplyLintFix(fix LintFix) error {
+	return t.ApplyLintFixContext(context.Background(), fix)
+}
+
+func (t *Traggo) ApplyLintFixContext(ctx context.Context, fix LintFix) error {
+	if fix.Stop {
+		_, err := t.StopAtContext(ctx, []int{fix.Task.Id}, fix.Task.End)
+		return err
+	}
+	return t.UpdateTaskFromContext(ctx, fix.Before, fix.Task)
+}
+
+// LintRecord is a LintIssue for machine-readable outputs
+type LintRecord struct {
+	Kind  string    `json:"kind"`
+	Ids   []int     `json:"ids"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Fix   string    `json:"fix,omitempty"` // proposed fix, empty if it has to be fixed by hand
+}
+
+func (l LintIssues) Records() []LintRecord {
+	records := make([]LintRecord, len(l))
+	for i, issue := range l {
+		ids := make([]int, len(issue.Tasks))
+		for k, task := range issue.Tasks {
+			ids[k] = task.GetId()
+		}
+		records[i] = LintRecord{Kind: issue.Kind, Ids: ids, Start: issue.Start, End: issue.End}
+		if issue.Fix != nil {
+			records[i].Fix = issue.Fix.Description
+		}
+	}
+	return records
+}
+
+// Header names the columns of Rows
+func (l LintIssues) Header() []string {
+	return []string{"kind", "ids", "start", "end", "fix"}
+}
+
+func (l LintIssues) Rows() [][]string {
+	rows := make([][]string, len(l))
+	for i, issue := range l {
+		ids := make([]string, len(issue.Tasks))
+		for k, task := range issue.Tasks {
+			ids[k] = fmt.Sprintf("%d", task.GetId())
+		}
+		fix := ""
+		if issue.Fix != nil {
+			fix = issue.Fix.Description
+		}
+		rows[i] = []string{
+			issue.Kind,
+			strings.Join(ids, ","),
+			issue.Start.Local().Format(time.DateTime),
+			issue.End.Local().Format(time.DateTime),
+			fix,
+		}
+	}
+	return rows
+}
+
+func (l LintIssues) PreparePretty(colors config.ColorsDef) string {
+	ta := table.New().
+		BorderStyle(BorderStyle).
+		Headers("Kind", "Ids", "Start", "End", "Fix").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return baseStyle.Foreground(colors.Table.HeaderStyle).Bold(true)
+			case row%2 == 0:
+				return baseStyle.Foreground(colors.Table.EvenStyle)
+			default:
+				return baseStyle.Foreground(colors.Table.OddStyle)
+			}
+		}).
+		Rows(l.Rows()...)
+	return ta.String()
+}
@@ -1,136 +1,139 @@
 package session
 
 import (
-	"log"
+	"context"
 	"time"
 )
 
+type timeSpansVariables struct {
+	Cursor CursorRequest `json:"cursor"`
+}
+
+type timeSpansBetweenVariables struct {
+	Start  time.Time     `json:"start"`
+	End    time.Time     `json:"end"`
+	Cursor CursorRequest `json:"cursor"`
+}
+
 // ListBetweenDates return []TimeSpanTask{} containing matching tasks
 // between the provided dates
-func (t *Traggo) ListBetweenDates(startDate time.Time, endDate time.Time) TimeSpanTaskList {
-	variables := struct {
-		Start  time.Time     `json:"start"`
-		End    time.Time     `json:"end"`
-		Cursor CursorRequest `json:"cursor"`
-	}{
-		Start:  startDate,
-		End:    endDate,
-		Cursor: CursorRequest{Offset: 0, PageSize: 10},
+func (t *Traggo) ListBetweenDates(startDate time.Time, endDate time.Time) (TimeSpanTaskList, error) {
+	return t.ListBetweenDatesContext(context.Background(), startDate, endDate)
+}
+
+func (t *Traggo) ListBetweenDatesContext(ctx context.Context, startDate time.Time, endDate time.Time) (TimeSpanTaskList, error) {
+	timeSpanTaskSlice := []TimeSpanTask{}
+	err := t.eachTimeSpansBetweenPage(ctx, startDate, endDate, func(page TimeSpans) bool {
+		timeSpanTaskSlice = append(timeSpanTaskSlice, page.TimeSpans...)
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
+	return timeSpanTaskSlice, nil
+}
 
-	op := Operation{
-		OperationName: "TimeSpans",
-		Variables:     variables,
-		Query:         "query TimeSpans($start: Time!, $end: Time!, $cursor: InputCursor) {\n  timeSpans(fromInclusive: $start, toInclusive: $end, cursor: $cursor) {\n    timeSpans {\n      id\n      start\n      end\n      tags {\n        key\n        value\n        __typename\n      }\n      oldStart\n      note\n      __typename\n    }\n    cursor {\n      hasMore\n    startId\n      offset\n      pageSize\n      __typename\n    }\n    __typename\n  }\n}\n",
+// eachTimeSpansBetweenPage walks through the time spans between the provided
+// dates, page by page. The walk stops as soon as fn returns false.
+func (t *Traggo) eachTimeSpansBetweenPage(ctx context.Context, startDate time.Time, endDate time.Time, fn func(TimeSpans) bool) error {
+	variables := timeSpansBetweenVariables{
+		Start:  startDate,
+		End:    endDate,
+		Cursor: CursorRequest{Offset: 0, PageSize: 100},
 	}
-	timeSpanTaskSlice := []TimeSpanTask{}
 	for {
-
-		var d TimeSpanRoot
-		err := t.Request(
-			"ListBetweenDates",
-			"POST",
-			op,
-			&d,
-		)
+		d, err := execute[TimeSpansData](ctx, t, qTimeSpansBetween, variables)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-
-		for _, timespan := range d.Data.TimeSpans.TimeSpans {
-			timeSpanTaskSlice = append(timeSpanTaskSlice, timespan)
+		if !fn(d.TimeSpans) {
+			return nil
 		}
-		if !d.Data.TimeSpans.Cursor.HasMore {
-			// stop the pagination loop
-			break
+		// stop the pagination loop
+		if !d.TimeSpans.Cursor.HasMore {
+			return nil
 		}
-		op.Variables = struct {
-			Start  time.Time     `json:"start"`
-			End    time.Time     `json:"end"`
-			Cursor CursorRequest `json:"cursor"`
-		}{
-			Start:  startDate,
-			End:    endDate,
-			Cursor: CursorRequest{Offset: d.Data.TimeSpans.Cursor.Offset, PageSize: 10},
+		variables.Cursor = CursorRequest{
+			StartId:  d.TimeSpans.Cursor.StartId,
+			Offset:   d.TimeSpans.Cursor.Offset,
+			PageSize: 100,
 		}
-
 	}
-	return timeSpanTaskSlice
 }
 
 // ListCurrentTasks return TimerTasks containing current running tasks
-func (t *Traggo) ListCurrentTasks() TimersData {
-	return t.ListCurrentTasksStartingAt(time.Time{})
+func (t *Traggo) ListCurrentTasks() (TimersData, error) {
+	return t.ListCurrentTasksStartingAtContext(context.Background(), time.Time{})
+}
+
+func (t *Traggo) ListCurrentTasksContext(ctx context.Context) (TimersData, error) {
+	return t.ListCurrentTasksStartingAtContext(ctx, time.Time{})
 }
 
 // ListCurrentTasksStartingAt return TimerTasks containing current running tasks
 // started from provided startDate and now
-func (t *Traggo) ListCurrentTasksStartingAt(startDateLimit time.Time) TimersData {
-	op := Operation{
-		OperationName: "Trackers",
-		Query:         "query Trackers {\n  timers {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}\n",
-	}
-	var tasks TimerTasks
-	err := t.Request(
-		"ListCurrentTasks",
-		"POST",
-		op,
-		&tasks,
-	)
+func (t *Traggo) ListCurrentTasksStartingAt(startDateLimit time.Time) (TimersData, error) {
+	return t.ListCurrentTasksStartingAtContext(context.Background(), startDateLimit)
+}
+
+func (t *Traggo) ListCurrentTasksStartingAtContext(ctx context.Context, startDateLimit time.Time) (TimersData, error) {
+	tasks, err := execute[TimersData](ctx, t, qTrackers, nil)
 	if err != nil {
-		log.Fatal(err)
+		return TimersData{}, err
 	}
+	t.Status.put(tasks)
 
 	if !startDateLimit.IsZero() {
 		ct := TimersData{}
-		for _, task := range tasks.Data.Timers {
+		for _, task := range tasks.Timers {
 			if task.Start.After(startDateLimit) {
 				ct.Timers = append(ct.Timers, task)
 			}
 		}
-		return ct
+		return ct, nil
 	}
 
-	return tasks.Data
+	return tasks, nil
 }
 
-func (t *Traggo) ListCompleteTasks() TimeSpanTaskList {
-	variables := struct {
-		Cursor CursorRequest `json:"cursor"`
-	}{
-		Cursor: CursorRequest{Offset: 0, PageSize: 100},
-	}
-	op := Operation{
-		OperationName: "TimeSpans",
-		Variables:     variables,
-		Query:         "query TimeSpans($cursor: InputCursor!) {\n  timeSpans(cursor: $cursor) {\n    timeSpans {\n      id\n      start\n      end\n      tags {\n        key\n        value\n        __typename\n      }\n      oldStart\n      note\n      __typename\n    }\n    cursor {hasMore\n      startId\n      offset\n      pageSize\n      __typename\n    }\n    __typename\n  }\n}\n",
-	}
+func (t *Traggo) ListCompleteTasks() (TimeSpanTaskList, error) {
+	return t.ListCompleteTasksContext(context.Background())
+}
 
+func (t *Traggo) ListCompleteTasksContext(ctx context.Context) (TimeSpanTaskList, error) {
 	var tasks TimeSpanTaskList
+	err := t.eachTimeSpansPage(ctx, func(page TimeSpans) bool {
+		tasks = append(tasks, page.TimeSpans...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
 
+// eachTimeSpansPage walks through all the time spans, page by page.
+// The walk stops as soon as fn returns false.
+func (t *Traggo) eachTimeSpansPage(ctx context.Context, fn func(TimeSpans) bool) error {
+	variables := timeSpansVariables{
+		Cursor: CursorRequest{Offset: 0, PageSize: 100},
+	}
 	for {
-		var d TimeSpanRoot
-		err := t.Request(
-			"ListCompleteTasks",
-			"POST",
-			op,
-			&d,
-		)
+		d, err := execute[TimeSpansData](ctx, t, qTimeSpans, variables)
 		if err != nil {
-			log.Fatal(err)
+			return err
+		}
+		if !fn(d.TimeSpans) {
+			return nil
 		}
-
-		tasks = append(tasks, d.Data.TimeSpans.TimeSpans...)
-
 		// stop the pagination loop
-		if !d.Data.TimeSpans.Cursor.HasMore {
-			break
+		if !d.TimeSpans.Cursor.HasMore {
+			return nil
 		}
-		op.Variables = struct {
-			Cursor CursorRequest `json:"cursor"`
-		}{
-			Cursor: CursorRequest{Offset: d.Data.TimeSpans.Cursor.Offset, PageSize: 100},
+		variables.Cursor = CursorRequest{
+			StartId:  d.TimeSpans.Cursor.StartId,
+			Offset:   d.TimeSpans.Cursor.Offset,
+			PageSize: 100,
 		}
 	}
-	return tasks
 }
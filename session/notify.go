@@ -0,0 +1,66 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DesktopNotifier shows alerts with the notification system of the desktop:
+// notify-send on Linux and BSD, osascript on macOS
+type DesktopNotifier struct{}
+
+func (DesktopNotifier) Notify(ctx context.Context, alert WatchAlert) error {
+	title := "Forgotten timer?"
+	message := alert.String()
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "windows":
+		return errors.New("desktop notifications are not supported on windows, use a command")
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=traggo_cli", title, message)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("desktop notification failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// CommandNotifier runs a shell command for each alert. The alert is given in
+// the environment: TRAGGO_TIMER_ID, TRAGGO_TIMER_TAGS (tag:value,...),
+// TRAGGO_TIMER_NOTE, TRAGGO_TIMER_START, TRAGGO_REASON and TRAGGO_AT
+// (RFC3339 dates).
+type CommandNotifier struct {
+	Command string
+}
+
+func (n CommandNotifier) Notify(ctx context.Context, alert WatchAlert) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", n.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", n.Command)
+	}
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("TRAGGO_TIMER_ID=%d", alert.Timer.Id),
+		"TRAGGO_TIMER_TAGS="+strings.Join(alert.Timer.ExportTags(), ","),
+		"TRAGGO_TIMER_NOTE="+alert.Timer.Note,
+		"TRAGGO_TIMER_START="+alert.Timer.Start.Format(time.RFC3339),
+		"TRAGGO_REASON="+alert.Reason,
+		"TRAGGO_AT="+alert.At.Format(time.RFC3339),
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("command '%s' failed: %w: %s", n.Command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrOverlap is returned by CheckOverlap when a task overlaps existing ones
+var ErrOverlap = errors.New("overlapping tasks")
+
+// overlapMargin is how long before a task time spans are looked for,
+// longer time spans are not expected
+const overlapMargin = 24 * time.Hour
+
+// overlaps tells if the periods [start1, end1[ and [start2, end2[ intersect
+func overlaps(start1, end1, start2, end2 time.Time) bool {
+	return start1.Before(end2) && start2.Before(end1)
+}
+
+// Overlapping returns the tasks overlapping task, which runs until now if it
+// has no end. A time span is also compared to the running timers, while a
+// timer is only compared to time spans as several timers may run together.
+// The task itself is ignored if it already exists.
+func (t *Traggo) Overlapping(task TimeSpanTask) ([]GenericTask, error) {
+	return t.OverlappingContext(context.Background(), task)
+}
+
+func (t *Traggo) OverlappingContext(ctx context.Context, task TimeSpanTask) ([]GenericTask, error) {
+	now := TimeNow()
+	end := task.End
+	if end.IsZero() {
+		end = now
+	}
+	var found []GenericTask
+	collect := func(other GenericTask, otherEnd time.Time) {
+		if (task.Id == 0 || other.GetId() != task.Id) && overlaps(task.Start, end, other.GetStart(), otherEnd) {
+			found = append(found, other)
+		}
+	}
+
+	if !task.End.IsZero() {
+		timers, err := t.ListCurrentTasksContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, timer := range timers.Timers {
+			collect(timer, now)
+		}
+	}
+	// a time span started before task may still run at its start
+	err := t.eachTimeSpansBetweenPage(ctx, task.Start.Add(-overlapMargin), end, func(page TimeSpans) bool {
+		for _, span := range page.TimeSpans {
+			collect(span, span.End)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// CheckOverlap returns an error wrapping ErrOverlap and describing the tasks
+// overlapping task, see Overlapping
+func (t *Traggo) CheckOverlap(task TimeSpanTask) error {
+	return t.CheckOverlapContext(context.Background(), task)
+}
+
+func (t *Traggo) CheckOverlapContext(ctx context.Context, task TimeSpanTask) error {
+	found, err := t.OverlappingContext(ctx, task)
+	if err != nil || len(found) == 0 {
+		return err
+	}
+	described := make([]string, len(found))
+	for i, other := range found {
+		span := asTimeSpan(other)
+		end := "now"
+		if !span.End.IsZero() {
+			end = span.End.Local().Format(time.DateTime)
+		}
+		described[i] = fmt.Sprintf("task %d [%s] from %s to %s", span.Id, strings.Join(span.ExportTags(), ","), span.Start.Local().Format(time.DateTime), end)
+	}
+	return fmt.Errorf("%w: %s", ErrOverlap, strings.Join(described, ", "))
+}
@@ -0,0 +1,136 @@
+package session
+
+// query is a GraphQL document known by the client.
+// Every request sent to Traggo goes through one entry of the registry below,
+// so the documents live in a single place.
+type query struct {
+	OperationName string // operationName sent along the document
+	Document      string
+}
+
+// Names of the registered queries
+const (
+	qLogin            = "Login"
+	qCurrentUser      = "CurrentUser"
+	qVersion          = "Version"
+	qSettings         = "Settings"
+	qTags             = "Tags"
+	qRemoveTag        = "RemoveTag"
+	qTrackers         = "Trackers"
+	qTimeSpans        = "TimeSpans"
+	qTimeSpansBetween = "TimeSpansBetween"
+	qStartTimer       = "StartTimer"
+	qStopTimer        = "StopTimer"
+	qRemoveTimeSpan   = "RemoveTimeSpan"
+	qUpdateTimer      = "UpdateTimer"
+	qUpdateTimeSpan   = "UpdateTimeSpan"
+	qContinue         = "Continue"
+	qStats            = "Stats"
+	qCreateTimeSpan   = "CreateTimeSpan"
+	qCreateTag        = "CreateTag"
+	qUpdateTag        = "UpdateTag"
+	qSetSettings      = "SetUserSettings"
+	qDashboards       = "Dashboards"
+	qCreateDashboard  = "CreateDashboard"
+	qAddDashRange     = "AddDashboardRange"
+	qAddDashEntry     = "AddDashboardEntry"
+)
+
+var queries = map[string]query{
+	qLogin: {
+		OperationName: "Login",
+		Document:      "mutation Login($name: String!, $pass: String!) {login(username: $name, pass: $pass, deviceName: \"test\", type: NoExpiry, cookie: false) {token user{id, name, admin, __typename}}}",
+	},
+	qCurrentUser: {
+		OperationName: "CurrentUser",
+		Document:      "query CurrentUser {\n  user: currentUser {\n    name\n    id\n  }\n}\n",
+	},
+	qVersion: {
+		OperationName: "Version",
+		Document:      "query Version {  version {    name    commit    buildDate    __typename  }}",
+	},
+	qSettings: {
+		OperationName: "Settings",
+		Document:      "query Settings {\n  userSettings {\n    theme\n    dateLocale\n    firstDayOfTheWeek\n    dateTimeInputStyle}\n}\n",
+	},
+	qTags: {
+		OperationName: "Tags",
+		Document:      "query Tags {\n  tags {\n    key\n    color\n    usages\n}\n}",
+	},
+	qRemoveTag: {
+		OperationName: "RemoveTag",
+		Document:      "mutation RemoveTag($key: String!) {\n  removeTag(key: $key) {\n    color\n    key\n  }\n}",
+	},
+	qTrackers: {
+		OperationName: "Trackers",
+		Document:      "query Trackers {\n  timers {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}\n",
+	},
+	qTimeSpans: {
+		OperationName: "TimeSpans",
+		Document:      "query TimeSpans($cursor: InputCursor!) {\n  timeSpans(cursor: $cursor) {\n    timeSpans {\n      id\n      start\n      end\n      tags {\n        key\n        value\n        __typename\n      }\n      oldStart\n      note\n      __typename\n    }\n    cursor {hasMore\n      startId\n      offset\n      pageSize\n      __typename\n    }\n    __typename\n  }\n}\n",
+	},
+	qTimeSpansBetween: {
+		OperationName: "TimeSpans",
+		Document:      "query TimeSpans($start: Time!, $end: Time!, $cursor: InputCursor) {\n  timeSpans(fromInclusive: $start, toInclusive: $end, cursor: $cursor) {\n    timeSpans {\n      id\n      start\n      end\n      tags {\n        key\n        value\n        __typename\n      }\n      oldStart\n      note\n      __typename\n    }\n    cursor {\n      hasMore\n    startId\n      offset\n      pageSize\n      __typename\n    }\n    __typename\n  }\n}\n",
+	},
+	qStartTimer: {
+		OperationName: "StartTimer",
+		Document:      "mutation StartTimer($start: Time!, $tags: [InputTimeSpanTag!], $note: String!) {\n  createTimeSpan(start: $start, tags: $tags, note: $note) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}\n",
+	},
+	qStopTimer: {
+		OperationName: "StopTimer",
+		Document:      "mutation StopTimer($id: Int!, $end: Time!) {\n  stopTimeSpan(id: $id, end: $end) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}\n",
+	},
+	qRemoveTimeSpan: {
+		OperationName: "RemoveTimeSpan",
+		Document:      "mutation RemoveTimeSpan($id: Int!) {\n  removeTimeSpan(id: $id) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    note\n    __typename\n  }\n}\n",
+	},
+	qUpdateTimer: {
+		OperationName: "UpdateTimeSpan",
+		Document:      "mutation UpdateTimeSpan($id: Int!, $start: Time!, $tags: [InputTimeSpanTag!], $note: String!) {\n  updateTimeSpan(id: $id, start: $start, tags: $tags, note: $note) {\n    id\n    start\n    tags {\n      key\n      value\n      __typename\n    }\n   note\n    __typename\n  }\n}\n",
+	},
+	qUpdateTimeSpan: {
+		OperationName: "UpdateTimeSpan",
+		Document:      "mutation UpdateTimeSpan($id: Int!, $start: Time!, $end: Time, $tags: [InputTimeSpanTag!], $oldStart: Time, $note: String!) {\n  updateTimeSpan(id: $id, start: $start, end: $end, tags: $tags, oldStart: $oldStart, note: $note) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}\n",
+	},
+	qContinue: {
+		OperationName: "Continue",
+		Document:      "mutation Continue($id: Int!, $start: Time!) {\n  copyTimeSpan(id: $id, start: $start) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}",
+	},
+	qCreateTimeSpan: {
+		OperationName: "CreateTimeSpan",
+		Document:      "mutation CreateTimeSpan($start: Time!, $end: Time, $tags: [InputTimeSpanTag!], $note: String!) {\n  createTimeSpan(start: $start, end: $end, tags: $tags, note: $note) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}\n",
+	},
+	qStats: {
+		OperationName: "Stats",
+		Document:      "query Stats($ranges: [Range!], $tags: [String!], $excludeTags: [InputTimeSpanTag!], $requireTags: [InputTimeSpanTag!]) {\n  stats(ranges: $ranges, tags: $tags, excludeTags: $excludeTags, requireTags: $requireTags) {\n    start\n    end\n    entries {\n      key\n      value\n      timeSpendInSeconds\n      __typename\n    }\n    __typename\n  }\n}\n",
+	},
+	qCreateTag: {
+		OperationName: "CreateTag",
+		Document:      "mutation CreateTag($key: String!, $color: String!) {\n  createTag(key: $key, color: $color) {\n    key\n    color\n    usages\n    __typename\n  }\n}\n",
+	},
+	qSetSettings: {
+		OperationName: "SetUserSettings",
+		Document:      "mutation SetUserSettings($settings: InputUserSettings!) {\n  setUserSettings(settings: $settings) {\n    theme\n    dateLocale\n    firstDayOfTheWeek\n    dateTimeInputStyle\n    __typename\n  }\n}\n",
+	},
+	qDashboards: {
+		OperationName: "Dashboards",
+		Document:      "query Dashboards {\n  dashboards {\n    id\n    name\n    items {\n      id\n      title\n      total\n      entryType\n      pos {\n        desktop {\n          x\n          y\n          w\n          h\n          minW\n          minH\n        }\n        mobile {\n          x\n          y\n          w\n          h\n          minW\n          minH\n        }\n      }\n      statsSelection {\n        interval\n        tags\n        excludeTags {\n          key\n          value\n        }\n        includeTags {\n          key\n          value\n        }\n        range {\n          from\n          to\n        }\n        rangeId\n      }\n    }\n    ranges {\n      id\n      name\n      editable\n      range {\n        from\n        to\n      }\n    }\n    __typename\n  }\n}\n",
+	},
+	qCreateDashboard: {
+		OperationName: "CreateDashboard",
+		Document:      "mutation CreateDashboard($name: String!) {\n  createDashboard(name: $name) {\n    id\n    name\n    __typename\n  }\n}\n",
+	},
+	qAddDashRange: {
+		OperationName: "AddDashboardRange",
+		Document:      "mutation AddDashboardRange($dashboardId: Int!, $range: InputNamedDateRange!) {\n  addDashboardRange(dashboardId: $dashboardId, range: $range) {\n    id\n    __typename\n  }\n}\n",
+	},
+	qAddDashEntry: {
+		OperationName: "AddDashboardEntry",
+		Document:      "mutation AddDashboardEntry($dashboardId: Int!, $entryType: EntryType!, $title: String!, $total: Boolean!, $stats: InputStatsSelection!, $pos: InputResponsiveDashboardEntryPos) {\n  addDashboardEntry(dashboardId: $dashboardId, entryType: $entryType, title: $title, total: $total, stats: $stats, pos: $pos) {\n    id\n    __typename\n  }\n}\n",
+	},
+	qUpdateTag: {
+		OperationName: "UpdateTag",
+		Document:      "mutation UpdateTag($key: String!, $newKey: String, $color: String!) {\n  updateTag(key: $key, newKey: $newKey, color: $color) {\n    key\n    color\n    usages\n    __typename\n  }\n}\n",
+	},
+}
@@ -0,0 +1,174 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrQueued is returned by a mutation saved in the offline queue
+// because Traggo was unreachable. Use Sync to send it later.
+var ErrQueued = errors.New("traggo is unreachable, operation queued")
+
+// QueuedOperation is a mutation waiting in the offline queue.
+// Variables are kept as sent, so timestamps are the original ones.
+type QueuedOperation struct {
+	Query     string          `json:"query"` // name in the queries registry
+	Variables json.RawMessage `json:"variables,omitempty"`
+	QueuedAt  time.Time       `json:"queuedAt"`
+}
+
+func (o QueuedOperation) String() string {
+	return fmt.Sprintf("%s %s (queued at %s)", o.Query, string(o.Variables), o.QueuedAt.Format(time.DateTime))
+}
+
+// Queue is the on-disk list of mutations to replay, oldest first
+type Queue struct {
+	Path string
+}
+
+func NewQueue(path string) *Queue {
+	return &Queue{Path: path}
+}
+
+// Load returns queued operations. A missing file is an empty queue.
+func (q *Queue) Load() ([]QueuedOperation, error) {
+	d, err := os.ReadFile(q.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ops []QueuedOperation
+	err = json.Unmarshal(d, &ops)
+	if err != nil {
+		return nil, fmt.Errorf("invalid queue file '%s': %w", q.Path, err)
+	}
+	return ops, nil
+}
+
+// Push appends the mutation at the end of the queue
+func (q *Queue) Push(name string, variables any) error {
+	ops, err := q.Load()
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(variables)
+	if err != nil {
+		return err
+	}
+	ops = append(ops, QueuedOperation{
+		Query:     name,
+		Variables: raw,
+		QueuedAt:  TimeNow(),
+	})
+	return q.Save(ops)
+}
+
+// Save replaces the queue content. An empty queue removes the file.
+func (q *Queue) Save(ops []QueuedOperation) error {
+	if len(ops) == 0 {
+		err := os.Remove(q.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	err := os.MkdirAll(filepath.Dir(q.Path), 0o770)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ops, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(q.Path, data, 0o600)
+}
+
+// mutate runs the registered mutation. If Traggo is unreachable and
+// a queue is configured, the mutation is queued and ErrQueued is returned.
+func mutate[T any](ctx context.Context, t *Traggo, name string, variables any) (T, error) {
+	d, err := execute[T](ctx, t, name, variables)
+	if err == nil || t.Queue == nil || !errors.Is(err, ErrUnreachable) {
+		return d, err
+	}
+	qErr := t.Queue.Push(name, variables)
+	if qErr != nil {
+		return d, errors.Join(err, qErr)
+	}
+	return d, ErrQueued
+}
+
+// SyncConflict is a queued operation rejected by the server
+type SyncConflict struct {
+	Operation QueuedOperation
+	Err       error
+}
+
+// SyncReport describes what happened to the queued operations
+type SyncReport struct {
+	Sent      []QueuedOperation
+	Conflicts []SyncConflict
+	Uncertain []SyncConflict    // failed after reaching Traggo, a timeout for example: they may have been applied
+	Pending   []QueuedOperation // not sent because Traggo is still unreachable
+}
+
+// Sync replays the queued operations in order.
+// Operations rejected by the server are reported as conflicts and kept
+// in the queue unless dropConflicts is set. Operations failing after they
+// may have reached Traggo are reported as uncertain and removed from the
+// queue, so they are never applied twice.
+func (t *Traggo) Sync(dropConflicts bool) (SyncReport, error) {
+	return t.SyncContext(context.Background(), dropConflicts)
+}
+
+func (t *Traggo) SyncContext(ctx context.Context, dropConflicts bool) (SyncReport, error) {
+	var report SyncReport
+	if t.Queue == nil {
+		return report, errors.New("no offline queue configured")
+	}
+	ops, err := t.Queue.Load()
+	if err != nil {
+		return report, err
+	}
+
+	for index, op := range ops {
+		q, ok := queries[op.Query]
+		if !ok {
+			report.Conflicts = append(report.Conflicts, SyncConflict{op, fmt.Errorf("unknown query '%s'", op.Query)})
+			continue
+		}
+		err := t.RequestContext(ctx, Operation{
+			OperationName: q.OperationName,
+			Variables:     op.Variables,
+			Query:         q.Document,
+		}, nil)
+		if errors.Is(err, ErrUnreachable) || ctx.Err() != nil {
+			report.Pending = ops[index:]
+			break
+		}
+		if err != nil && !rejected(err) {
+			report.Uncertain = append(report.Uncertain, SyncConflict{op, err})
+			continue
+		}
+		if err != nil {
+			report.Conflicts = append(report.Conflicts, SyncConflict{op, err})
+			continue
+		}
+		report.Sent = append(report.Sent, op)
+	}
+
+	var remaining []QueuedOperation
+	if !dropConflicts {
+		for _, c := range report.Conflicts {
+			remaining = append(remaining, c.Operation)
+		}
+	}
+	remaining = append(remaining, report.Pending...)
+	return report, t.Queue.Save(remaining)
+}
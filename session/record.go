@@ -0,0 +1,76 @@
+package session
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecordHeader names the fields of a Record, in the order of Record.Fields
+var RecordHeader = []string{"id", "tags", "start", "end", "duration", "note"}
+
+// Record is the machine readable form of a task. Its field names are stable
+// and used by JSON, CSV and TSV outputs.
+type Record struct {
+	Id       int        `json:"id"`
+	Tags     []string   `json:"tags"` // TagName:TagValue
+	Start    time.Time  `json:"start"`
+	End      *time.Time `json:"end"`      // null for a running task
+	Duration int64      `json:"duration"` // in seconds, up to now for a running task
+	Note     string     `json:"note"`
+}
+
+// Fields returns the record values as strings. Tags are separated by ';'
+// and dates use RFC 3339.
+func (r Record) Fields() []string {
+	end := ""
+	if r.End != nil {
+		end = r.End.Format(time.RFC3339)
+	}
+	return []string{
+		strconv.Itoa(r.Id),
+		strings.Join(r.Tags, ";"),
+		r.Start.Format(time.RFC3339),
+		end,
+		strconv.FormatInt(r.Duration, 10),
+		r.Note,
+	}
+}
+
+func (t TimerTask) Record() Record {
+	tags := t.ExportTags()
+	if tags == nil {
+		tags = []string{}
+	}
+	return Record{
+		Id:       t.Id,
+		Tags:     tags,
+		Start:    t.Start,
+		Duration: int64(TimeNow().Sub(t.Start).Round(time.Second) / time.Second),
+		Note:     t.Note,
+	}
+}
+
+func (t TimeSpanTask) Record() Record {
+	r := t.TimerTask.Record()
+	end := t.End
+	r.End = &end
+	r.Duration = int64(t.End.Sub(t.Start).Round(time.Second) / time.Second)
+	return r
+}
+
+func (t TimersData) Records() []Record {
+	records := make([]Record, 0, len(t.Timers))
+	for _, task := range t.Timers {
+		records = append(records, task.Record())
+	}
+	return records
+}
+
+func (t TimeSpanTaskList) Records() []Record {
+	records := make([]Record, 0, len(t))
+	for _, task := range t {
+		records = append(records, task.Record())
+	}
+	return records
+}
@@ -0,0 +1,259 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/kalidor/traggo_cli/config"
+)
+
+// Intervals used to split a report
+const (
+	IntervalNone  = ""
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// ReportLine is the time spent on a combination of tag values
+type ReportLine struct {
+	Values   []string `json:"values"`   // in the order of Report.Keys, empty if the tag is missing
+	Duration int64    `json:"duration"` // in seconds
+}
+
+// ReportPeriod gathers the lines of one day, week or month
+type ReportPeriod struct {
+	Start    time.Time    `json:"start,omitzero"` // zero if the report is not split
+	Lines    []ReportLine `json:"lines"`
+	Duration int64        `json:"duration"` // subtotal in seconds
+}
+
+// Report is the time spent between Start and End, grouped by the values of Keys
+type Report struct {
+	Keys     []string       `json:"keys"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Interval string         `json:"interval,omitempty"`
+	Periods  []ReportPeriod `json:"periods"`
+	Duration int64          `json:"duration"` // total in seconds
+}
+
+// periodStart returns the beginning of the day, week (monday) or month of d
+func periodStart(d time.Time, interval string) time.Time {
+	day := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, d.Location())
+	switch interval {
+	case IntervalDay:
+		return day
+	case IntervalWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case IntervalMonth:
+		return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, d.Location())
+	}
+	return time.Time{}
+}
+
+// periodEnd returns the beginning of the period following the one starting at start
+func periodEnd(start time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalDay:
+		return start.AddDate(0, 0, 1)
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return time.Time{}
+}
+
+// tagValues returns the values of keys for task. Several values of
+// the same key are joined with ','.
+func tagValues(task TimerTask, keys []string) []string {
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		var v []string
+		for _, tag := range task.Tags {
+			if tag.Key == key {
+				v = append(v, tag.Value)
+			}
+		}
+		values[i] = strings.Join(v, ",")
+	}
+	return values
+}
+
+// BuildReport sums the time spent by tasks between start and end.
+// Running timers count up to now. Tasks are cut at the range limits and,
+// with an interval, at the limits of each period.
+func BuildReport(timers TimersData, spans TimeSpanTaskList, keys []string, interval string, start, end time.Time) (Report, error) {
+	switch interval {
+	case IntervalNone, IntervalDay, IntervalWeek, IntervalMonth:
+	default:
+		return Report{}, fmt.Errorf("invalid interval '%s', expected day, week or month", interval)
+	}
+	report := Report{Keys: keys, Start: start, End: end, Interval: interval, Periods: []ReportPeriod{}}
+	if report.Keys == nil {
+		report.Keys = []string{}
+	}
+
+	// durations by period then by joined tag values
+	sums := map[time.Time]map[string]time.Duration{}
+	add := func(task TimerTask, from, to time.Time) {
+		// periods are cut in local time
+		from, to = from.Local(), to.Local()
+		if from.Before(start) {
+			from = start.Local()
+		}
+		if to.After(end) {
+			to = end.Local()
+		}
+		values := strings.Join(tagValues(task, keys), "\x00")
+		for from.Before(to) {
+			pStart := periodStart(from, interval)
+			pEnd := to
+			if interval != IntervalNone && periodEnd(pStart, interval).Before(to) {
+				pEnd = periodEnd(pStart, interval)
+			}
+			if sums[pStart] == nil {
+				sums[pStart] = map[string]time.Duration{}
+			}
+			sums[pStart][values] += pEnd.Sub(from)
+			from = pEnd
+		}
+	}
+	now := TimeNow()
+	for _, task := range timers.Timers {
+		add(task, task.Start, now)
+	}
+	for _, task := range spans {
+		add(task.TimerTask, task.Start, task.End)
+	}
+
+	for pStart, lines := range sums {
+		period := ReportPeriod{Start: pStart}
+		for values, d := range lines {
+			seconds := int64(d.Round(time.Second) / time.Second)
+			v := []string{}
+			if len(keys) > 0 {
+				v = strings.Split(values, "\x00")
+			}
+			period.Lines = append(period.Lines, ReportLine{Values: v, Duration: seconds})
+			period.Duration += seconds
+		}
+		sort.Slice(period.Lines, func(i, j int) bool {
+			if period.Lines[i].Duration != period.Lines[j].Duration {
+				return period.Lines[i].Duration > period.Lines[j].Duration
+			}
+			return strings.Join(period.Lines[i].Values, ":") < strings.Join(period.Lines[j].Values, ":")
+		})
+		report.Periods = append(report.Periods, period)
+		report.Duration += period.Duration
+	}
+	sort.Slice(report.Periods, func(i, j int) bool {
+		return report.Periods[i].Start.Before(report.Periods[j].Start)
+	})
+	return report, nil
+}
+
+// Report returns the time spent between start and end grouped by the values of keys,
+// split by interval (IntervalNone, IntervalDay, IntervalWeek or IntervalMonth)
+func (t *Traggo) Report(start, end time.Time, keys []string, interval string) (Report, error) {
+	return t.ReportContext(context.Background(), start, end, keys, interval)
+}
+
+func (t *Traggo) ReportContext(ctx context.Context, start, end time.Time, keys []string, interval string) (Report, error) {
+	spans, err := t.ListBetweenDatesContext(ctx, start, end)
+	if err != nil {
+		return Report{}, err
+	}
+	timers, err := t.ListCurrentTasksContext(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	return BuildReport(timers, spans, keys, interval, start, end)
+}
+
+// periodName formats the start of a period for display
+func (r Report) periodName(p ReportPeriod) string {
+	switch r.Interval {
+	case IntervalDay:
+		return p.Start.Format(time.DateOnly)
+	case IntervalWeek:
+		year, week := p.Start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case IntervalMonth:
+		return p.Start.Format("2006-01")
+	}
+	return ""
+}
+
+// Rows returns one row per line: the period, the tag values and the duration in seconds
+func (r Report) Rows() [][]string {
+	var rows [][]string
+	for _, p := range r.Periods {
+		for _, l := range p.Lines {
+			row := append([]string{r.periodName(p)}, l.Values...)
+			rows = append(rows, append(row, fmt.Sprintf("%d", l.Duration)))
+		}
+	}
+	return rows
+}
+
+// Header names the columns of Rows
+func (r Report) Header() []string {
+	return append(append([]string{"period"}, r.Keys...), "duration")
+}
+
+func formatSeconds(s int64) string {
+	return (time.Duration(s) * time.Second).String()
+}
+
+func (r Report) PreparePretty(colors config.ColorsDef) string {
+	var rows [][]string
+	subtotal := map[int]bool{}
+	empty := make([]string, len(r.Keys))
+	for _, p := range r.Periods {
+		for _, l := range p.Lines {
+			values := make([]string, len(l.Values))
+			for i, v := range l.Values {
+				values[i] = v
+				if v == "" {
+					values[i] = "-"
+				}
+			}
+			row := append([]string{r.periodName(p)}, values...)
+			rows = append(rows, append(row, formatSeconds(l.Duration)))
+		}
+		if r.Interval != IntervalNone {
+			subtotal[len(rows)] = true
+			row := append([]string{r.periodName(p) + " subtotal"}, empty...)
+			rows = append(rows, append(row, formatSeconds(p.Duration)))
+		}
+	}
+	subtotal[len(rows)] = true
+	row := append([]string{"total"}, empty...)
+	rows = append(rows, append(row, formatSeconds(r.Duration)))
+
+	headers := append(append([]string{"Period"}, r.Keys...), "Time")
+	ta := table.New().
+		BorderStyle(BorderStyle).
+		Headers(headers...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return baseStyle.Foreground(colors.Table.HeaderStyle).Bold(true)
+			case subtotal[row]:
+				return CellStyle.Foreground(colors.Table.HeaderStyle).Bold(true)
+			case row%2 == 0:
+				return CellStyle.Foreground(colors.Table.EvenStyle)
+			default:
+				return CellStyle.Foreground(colors.Table.OddStyle)
+			}
+		}).
+		Rows(rows...)
+	return ta.String()
+}
@@ -0,0 +1,324 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/kalidor/traggo_cli/config"
+)
+
+// OpRetag is the journal operation of ApplyRetag
+const OpRetag = "retag"
+
+// RetagRule renames the tags matching a key and a value.
+// With globs, '*' and '?' of the replacement take the text matched by the
+// '*' and '?' of the pattern, in order. With regular expressions, the
+// replacement refers to groups with $1, ${name}...
+type RetagRule struct {
+	key, value       *regexp.Regexp
+	newKey, newValue string // templates expanded with the matches
+}
+
+// globToRegexp converts a glob to an anchored regular expression, each
+// wildcard being a group, and returns the number of groups
+func globToRegexp(glob string) (string, int) {
+	var b strings.Builder
+	groups := 0
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString("(.*)")
+			groups++
+		case '?':
+			b.WriteString("(.)")
+			groups++
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String(), groups
+}
+
+// globTemplate converts the wildcards of a glob replacement to group references
+func globTemplate(glob string, groups int) (string, error) {
+	var b strings.Builder
+	n := 0
+	for _, r := range glob {
+		switch r {
+		case '*', '?':
+			n++
+			if n > groups {
+				return "", fmt.Errorf("'%s' has more wildcards than the pattern", glob)
+			}
+			fmt.Fprintf(&b, "${%d}", n)
+		case '$':
+			b.WriteString("$$")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), nil
+}
+
+// NewRetagRule builds a rule from TagName:TagValue patterns. An empty
+// value pattern matches any value, which is kept if the replacement value
+// is empty too. useRegexp selects regular expressions instead of globs.
+func NewRetagRule(from, to string, useRegexp bool) (RetagRule, error) {
+	fromKey, fromValue, _ := strings.Cut(from, ":")
+	toKey, toValue, ok := strings.Cut(to, ":")
+	if fromKey == "" || toKey == "" || !ok {
+		return RetagRule{}, fmt.Errorf("expected TagName:TagValue patterns, got '%s' and '%s'", from, to)
+	}
+	if fromValue == "" {
+		fromValue = "*"
+		if useRegexp {
+			fromValue = "(.*)"
+		}
+		if toValue == "" {
+			toValue = "*"
+			if useRegexp {
+				toValue = "$1"
+			}
+		}
+	}
+
+	var rule RetagRule
+	var err error
+	compile := func(pattern, replacement string) (*regexp.Regexp, string, error) {
+		if useRegexp {
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			return re, replacement, err
+		}
+		expr, groups := globToRegexp(pattern)
+		template, err := globTemplate(replacement, groups)
+		if err != nil {
+			return nil, "", err
+		}
+		return regexp.MustCompile(expr), template, nil
+	}
+	rule.key, rule.newKey, err = compile(fromKey, toKey)
+	if err != nil {
+		return RetagRule{}, fmt.Errorf("invalid tag name pattern: %w", err)
+	}
+	rule.value, rule.newValue, err = compile(fromValue, toValue)
+	if err != nil {
+		return RetagRule{}, fmt.Errorf("invalid tag value pattern: %w", err)
+	}
+	return rule, nil
+}
+
+func expand(re *regexp.Regexp, template, s string) string {
+	return string(re.ExpandString(nil, template, s, re.FindStringSubmatchIndex(s)))
+}
+
+// Apply returns tags once renamed, and whether one has changed.
+// Duplicated tags are removed.
+func (r RetagRule) Apply(tags []Tag) ([]Tag, bool) {
+	renamed := make([]Tag, 0, len(tags))
+	changed := false
+	seen := map[Tag]bool{}
+	for _, tag := range tags {
+		if r.key.MatchString(tag.Key) && r.value.MatchString(tag.Value) {
+			newTag := Tag{Key: expand(r.key, r.newKey, tag.Key), Value: expand(r.value, r.newValue, tag.Value)}
+			changed = changed || newTag != tag
+			tag = newTag
+		}
+		if seen[tag] {
+			changed = true
+			continue
+		}
+		seen[tag] = true
+		renamed = append(renamed, tag)
+	}
+	return renamed, changed
+}
+
+// RetagChange is a task whose tags are renamed
+type RetagChange struct {
+	Task GenericTask
+	Tags []Tag // new tags
+}
+
+type RetagChanges []RetagChange
+
+// PlanRetag returns the tasks selected by filter having tags renamed by rule.
+// The new tag names must be known by Traggo, see CreateTag.
+func (t *Traggo) PlanRetag(rule RetagRule, filter TaskFilter) (RetagChanges, error) {
+	return t.PlanRetagContext(context.Background(), rule, filter)
+}
+
+func (t *Traggo) PlanRetagContext(ctx context.Context, rule RetagRule, filter TaskFilter) (RetagChanges, error) {
+	tasks, err := t.SearchTasksContext(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var changes RetagChanges
+	for _, task := range tasks {
+		tags, changed := rule.Apply(asTimeSpan(task).Tags)
+		if changed {
+			changes = append(changes, RetagChange{Task: task, Tags: tags})
+		}
+	}
+	if len(changes) == 0 {
+		return changes, nil
+	}
+
+	// unknown names would make every update fail
+	known, err := t.GetTagsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, change := range changes {
+		for _, tag := range change.Tags {
+			if !known.Has(tag.Key) && !slices.Contains(missing, tag.Key) {
+				missing = append(missing, tag.Key)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unknown tags: %s, create them with 'traggo_cli tag create'", strings.Join(missing, ","))
+	}
+	return changes, nil
+}
+
+// RetagFailure is a change rejected by Traggo
+type RetagFailure struct {
+	Change RetagChange
+	Err    error
+}
+
+// RetagReport describes what ApplyRetag did
+type RetagReport struct {
+	Updated  int
+	Queued   int // Traggo became unreachable, see Sync
+	Failures []RetagFailure
+}
+
+// ApplyRetag updates the tasks of changes one by one. progress, if not nil,
+// is called every batchSize changes with the number of changes done.
+// A failing update does not stop the others. The whole run is a single
+// operation of the journal.
+func (t *Traggo) ApplyRetag(changes RetagChanges, batchSize int, progress func(done, total int)) (RetagReport, error) {
+	return t.ApplyRetagContext(context.Background(), changes, batchSize, progress)
+}
+
+func (t *Traggo) ApplyRetagContext(ctx context.Context, changes RetagChanges, batchSize int, progress func(done, total int)) (RetagReport, error) {
+	var report RetagReport
+	if batchSize <= 0 {
+		batchSize = len(changes)
+	}
+	var journal []JournalChange
+	defer func() { t.Journal.record(OpRetag, journal...) }()
+
+	for start := 0; start < len(changes); start += batchSize {
+		end := min(start+batchSize, len(changes))
+		for _, change := range changes[start:end] {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			before := asTimeSpan(change.Task)
+			task := before
+			task.Tags = change.Tags
+			var after TimeSpanTask
+			var err error
+			if task.End.IsZero() {
+				after, err = t.updateTimerTask(ctx, task.TimerTask)
+			} else {
+				after, err = t.updateTimeSpanTask(ctx, task)
+			}
+			switch {
+			case errors.Is(err, ErrQueued):
+				report.Queued++
+			case err != nil:
+				report.Failures = append(report.Failures, RetagFailure{Change: change, Err: err})
+			default:
+				report.Updated++
+				journal = append(journal, JournalChange{Before: &before, After: &after})
+			}
+		}
+		if progress != nil {
+			progress(end, len(changes))
+		}
+	}
+	return report, nil
+}
+
+func joinTags(tags []Tag) string {
+	s := make([]string, len(tags))
+	for i, tag := range tags {
+		s[i] = tag.Key + ":" + tag.Value
+	}
+	return strings.Join(s, ";")
+}
+
+// RetagRecord is a RetagChange for machine-readable outputs
+type RetagRecord struct {
+	Id      int       `json:"id"`
+	Start   time.Time `json:"start"`
+	Tags    []string  `json:"tags"`    // TagName:TagValue
+	NewTags []string  `json:"newTags"` // TagName:TagValue
+}
+
+func (c RetagChanges) Records() []RetagRecord {
+	records := make([]RetagRecord, len(c))
+	for i, change := range c {
+		records[i] = RetagRecord{
+			Id:      change.Task.GetId(),
+			Start:   change.Task.GetStart(),
+			Tags:    asTimeSpan(change.Task).ExportTags(),
+			NewTags: TimerTask{Tags: change.Tags}.ExportTags(),
+		}
+	}
+	return records
+}
+
+// Header names the columns of Rows
+func (c RetagChanges) Header() []string {
+	return []string{"id", "start", "tags", "newTags"}
+}
+
+func (c RetagChanges) Rows() [][]string {
+	rows := make([][]string, len(c))
+	for i, change := range c {
+		rows[i] = []string{
+			fmt.Sprintf("%d", change.Task.GetId()),
+			change.Task.GetStart().Local().Format(time.DateTime),
+			joinTags(asTimeSpan(change.Task).Tags),
+			joinTags(change.Tags),
+		}
+	}
+	return rows
+}
+
+func (c RetagChanges) PreparePretty(colors config.ColorsDef) string {
+	rows := c.Rows()
+	for _, row := range rows {
+		row[2] = strings.ReplaceAll(row[2], ";", "\n")
+		row[3] = strings.ReplaceAll(row[3], ";", "\n")
+	}
+	ta := table.New().
+		BorderStyle(BorderStyle).
+		Headers("ID", "Start", "Tags", "New tags").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return baseStyle.Foreground(colors.Table.HeaderStyle).Bold(true)
+			case row%2 == 0:
+				return baseStyle.Foreground(colors.Table.EvenStyle)
+			default:
+				return baseStyle.Foreground(colors.Table.OddStyle)
+			}
+		}).
+		Rows(rows...)
+	return ta.String()
+}
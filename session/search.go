@@ -1,94 +1,233 @@
 package session
 
 import (
-	"bytes"
-	"encoding/json"
-	"log"
-	"net/http"
+	"context"
+	"slices"
+	"strings"
+	"time"
 )
 
-// SearchTask by TaskID in current running tasks and already done tasks.
-func (t *Traggo) SearchTask(id int) GenericTask {
+// TaskFilter selects tasks in SearchTasks. Empty fields match everything.
+type TaskFilter struct {
+	Id       int
+	Ids      []int // any of these ids
+	TagKey   string
+	TagValue string // any value of TagKey matches if empty
+	Tags     []Tag  // all of these tags, any value matches if empty
+	Note     string // case insensitive substring of the note
+	From     time.Time
+	To       time.Time
+	Limit    int // stop after this number of matches (no limit if 0)
+}
 
-	//Search for current running tasks (Trackers)
-	for _, task := range t.ListCurrentTasks().Timers {
-		if task.Id == id {
-			return task
-		}
+// Match tells if task is selected by the filter
+func (f TaskFilter) Match(task GenericTask) bool {
+	if f.Id != 0 && task.GetId() != f.Id {
+		return false
+	}
+	if len(f.Ids) > 0 && !slices.Contains(f.Ids, task.GetId()) {
+		return false
 	}
-	//Search for old tasks
-	all := t.ListCompleteTasks()
-	for _, task := range all {
-		if task.Id == id {
-			return task
+	if f.TagKey != "" && !task.HasTag(f.TagKey, f.TagValue) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !task.HasTag(tag.Key, tag.Value) {
+			return false
 		}
 	}
-	return nil
+	if f.Note != "" && !strings.Contains(strings.ToLower(task.GetNote()), strings.ToLower(f.Note)) {
+		return false
+	}
+	if !f.From.IsZero() && task.GetStart().Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && task.GetStart().After(f.To) {
+		return false
+	}
+	return true
 }
 
-// SearchTaskByTag look for task matching provided tagName and tagValue.
-func (t *Traggo) SearchTaskByTag(tagName, tagValue string) GenericTask {
+// dateBounded tells if the filter can be sent as a date range to Traggo
+func (f TaskFilter) dateBounded() bool {
+	return !f.From.IsZero() || !f.To.IsZero()
+}
+
+// SearchTasks returns current running tasks then already done tasks matching
+// the filter, newest first.
+// With a cache, the search is done locally. Otherwise, time spans are
+// requested between From and To if set, and the pagination stops as soon as
+// Limit is reached or the requested ids are found.
+func (t *Traggo) SearchTasks(filter TaskFilter) ([]GenericTask, error) {
+	return t.SearchTasksContext(context.Background(), filter)
+}
+
+func (t *Traggo) SearchTasksContext(ctx context.Context, filter TaskFilter) ([]GenericTask, error) {
+	var found []GenericTask
+	// collect adds task if it matches and tells if the search should continue
+	collect := func(task GenericTask) bool {
+		if filter.Match(task) {
+			found = append(found, task)
+		}
+		if len(filter.Ids) > 0 && len(found) >= len(filter.Ids) {
+			return false
+		}
+		return filter.Limit <= 0 || len(found) < filter.Limit
+	}
 
-	//Search for current running tasks (Trackers)
-	for _, task := range t.ListCurrentTasks().Timers {
-		for _, taskTag := range task.Tags {
-			if taskTag.Key == tagName && taskTag.Value == tagValue {
-				return task
+	if t.Cache != nil {
+		if filter.Id != 0 {
+			task, err := t.cachedTask(ctx, filter.Id)
+			if err != nil || task == nil || !filter.Match(task) {
+				return nil, err
 			}
+			return []GenericTask{task}, nil
 		}
+		timers, spans, err := t.AllTasksContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range timers.Timers {
+			if !collect(task) {
+				return found, nil
+			}
+		}
+		for _, task := range spans {
+			if !collect(task) {
+				return found, nil
+			}
+		}
+		return found, nil
 	}
-	variables := struct {
-		Cursor CursorRequest `json:"cursor"`
-	}{
-		Cursor: CursorRequest{Offset: 0, PageSize: 100},
+
+	timers, err := t.ListCurrentTasksContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	//Search for old tasks
-	op := Operation{
-		OperationName: "TimeSpans",
-		Variables:     variables,
-		Query:         "query TimeSpans($cursor: InputCursor!) {\n  timeSpans(cursor: $cursor) {\n    timeSpans {\n      id\n      start\n      end\n      tags {\n        key\n        value\n        __typename\n      }\n      oldStart\n      note\n      __typename\n    }\n    cursor {hasMore\n      startId\n      offset\n      pageSize\n      __typename\n    }\n    __typename\n  }\n}\n",
+	for _, task := range timers.Timers {
+		if !collect(task) {
+			return found, nil
+		}
+	}
+	if filter.Id != 0 && len(found) > 0 {
+		return found, nil
 	}
 
-	for {
-		var body []byte
-		body, err := json.Marshal(op)
-		if err != nil {
-			panic(err)
+	page := func(page TimeSpans) bool {
+		// ids above startId do not exist yet
+		if filter.Id != 0 && page.Cursor.StartId != 0 && filter.Id > page.Cursor.StartId {
+			return false
 		}
-		req, err := http.NewRequest("POST", t.Url, bytes.NewReader(body))
-		if err != nil {
-			log.Fatal(err)
+		for _, task := range page.TimeSpans {
+			if !collect(task) {
+				return false
+			}
 		}
-		req.Header.Add("Content-Type", "application/json")
-		req.Header.Add("Cookie", t.Token)
+		return filter.Id == 0 || len(found) == 0
+	}
+	if filter.dateBounded() {
+		end := filter.To
+		if end.IsZero() {
+			end = TimeNow()
+		}
+		err = t.eachTimeSpansBetweenPage(ctx, filter.From, end, page)
+	} else {
+		err = t.eachTimeSpansPage(ctx, page)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
 
-		res, err := http.DefaultClient.Do(req)
-		if err != nil {
-			log.Fatal(err)
+// SearchTask by TaskID in current running tasks and already done tasks.
+// A nil GenericTask is returned if the id does not exist.
+func (t *Traggo) SearchTask(id int) (GenericTask, error) {
+	return t.SearchTaskContext(context.Background(), id)
+}
+
+func (t *Traggo) SearchTaskContext(ctx context.Context, id int) (GenericTask, error) {
+	return t.searchFirst(ctx, TaskFilter{Id: id, Limit: 1})
+}
+
+// SearchTaskByTag look for the newest task matching provided tagName and tagValue.
+// A nil GenericTask is returned if nothing matches.
+func (t *Traggo) SearchTaskByTag(tagName, tagValue string) (GenericTask, error) {
+	return t.SearchTaskByTagContext(context.Background(), tagName, tagValue)
+}
+
+func (t *Traggo) SearchTaskByTagContext(ctx context.Context, tagName, tagValue string) (GenericTask, error) {
+	return t.searchFirst(ctx, TaskFilter{TagKey: tagName, TagValue: tagValue, Limit: 1})
+}
+
+func (t *Traggo) searchFirst(ctx context.Context, filter TaskFilter) (GenericTask, error) {
+	found, err := t.SearchTasksContext(ctx, filter)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return found[0], nil
+}
+
+// LatestTimeSpan returns the most recently started time span matching
+// filter, running timers are ignored. A nil GenericTask is returned if
+// nothing matches.
+// Time spans come newest first, so the search stops after the first page
+// having a match.
+func (t *Traggo) LatestTimeSpan(filter TaskFilter) (GenericTask, error) {
+	return t.LatestTimeSpanContext(context.Background(), filter)
+}
+
+func (t *Traggo) LatestTimeSpanContext(ctx context.Context, filter TaskFilter) (GenericTask, error) {
+	var latest GenericTask
+	collect := func(spans TimeSpanTaskList) {
+		for _, span := range spans {
+			if filter.Match(span) && (latest == nil || span.Start.After(latest.GetStart())) {
+				latest = span
+			}
 		}
-		if res.StatusCode != 200 {
-			log.Fatal("List task failure")
+	}
+
+	if t.Cache != nil {
+		_, spans, err := t.AllTasksContext(ctx)
+		if err != nil {
+			return nil, err
 		}
+		collect(spans)
+		return latest, nil
+	}
 
-		var d TimeSpanRoot
-		json.NewDecoder(res.Body).Decode(&d)
-		for _, task := range d.Data.TimeSpans.TimeSpans {
-			for _, taskTag := range task.Tags {
-				if taskTag.Key == tagName && taskTag.Value == tagValue {
-					return task
-				}
-			}
+	page := func(page TimeSpans) bool {
+		collect(page.TimeSpans)
+		return latest == nil
+	}
+	var err error
+	if filter.dateBounded() {
+		end := filter.To
+		if end.IsZero() {
+			end = TimeNow()
 		}
-		if d.Data.TimeSpans.Cursor.HasMore {
-			op.Variables = struct {
-				Cursor CursorRequest `json:"cursor"`
-			}{
-				Cursor: CursorRequest{Offset: d.Data.TimeSpans.Cursor.Offset, PageSize: 100},
-			}
-		} else {
-			// stop the pagination loop
-			break
+		err = t.eachTimeSpansBetweenPage(ctx, filter.From, end, page)
+	} else {
+		err = t.eachTimeSpansPage(ctx, page)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return latest, nil
+}
+
+// SplitTasks separates current running tasks from already done tasks,
+// keeping their order
+func SplitTasks(tasks []GenericTask) (TimersData, TimeSpanTaskList) {
+	var timers TimersData
+	var spans TimeSpanTaskList
+	for _, task := range tasks {
+		switch task := task.(type) {
+		case TimerTask:
+			timers.Timers = append(timers.Timers, task)
+		case TimeSpanTask:
+			spans = append(spans, task)
 		}
 	}
-	return nil
+	return timers, spans
 }
@@ -1,31 +1,61 @@
 package session
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/kalidor/traggo_cli/config"
 )
 
 type Traggo struct {
-	Token  string
-	Url    string
-	Colors config.ColorsDef
-	Tags   config.TagsDef
+	Token   string
+	Url     string
+	Colors  config.ColorsDef
+	Tags    config.TagsDef
+	Timeout time.Duration // maximum duration of one request
+	Retries int           // retries of a failing query, mutations are never retried
+	Backoff time.Duration // delay before the first retry
+	Queue   *Queue        // mutations are queued here when Traggo is unreachable (disabled if nil)
+	Cache   *Cache        // local copy of the tasks used by searches (disabled if nil)
+	Journal *Journal      // mutations recorded for undo (disabled if nil)
+	Status  *StatusCache  // running timers kept for status lines (disabled if nil)
+	client  *http.Client
 }
 
 func NewTraggoSession(config *config.Config) *Traggo {
+	var queue *Queue
+	if config.Offline.Queue != "" {
+		queue = NewQueue(config.Offline.Queue)
+	}
+	var cache *Cache
+	if config.Cache.Path != "" && !config.Cache.Disabled {
+		cache = NewCache(config.Cache.Path, config.Cache.TTL.Duration, config.Cache.FullRefresh.Duration)
+	}
+	var journal *Journal
+	if config.Undo.Dir != "" && !config.Undo.Disabled {
+		journal = NewJournal(filepath.Join(config.Undo.Dir, "journal.json"), config.Undo.Size)
+	}
+	var status *StatusCache
+	if config.Status.Path != "" {
+		status = NewStatusCache(config.Status.Path, config.Status.TTL.Duration, config.Status.Timeout.Duration)
+	}
 	return &Traggo{
-		Url:    config.Auth.Url,
-		Token:  config.Auth.Token,
-		Colors: config.Colors,
-		Tags:   config.Tags,
+		Url:     config.Auth.Url,
+		Token:   config.Auth.Token,
+		Colors:  config.Colors,
+		Tags:    config.Tags,
+		Timeout: config.Client.Timeout.Duration,
+		Retries: config.Client.Retries,
+		Backoff: config.Client.Backoff.Duration,
+		Queue:   queue,
+		Cache:   cache,
+		Journal: journal,
+		Status:  status,
+		client:  http.DefaultClient,
 	}
 }
 
@@ -50,101 +80,80 @@ type TraggoCheckResponse struct {
 	Data TraggoUser `json:"data"`
 }
 
-func (t *Traggo) Request(command, method string, postBody, model any) error {
-	var body []byte
-	body, err := json.Marshal(postBody)
-	if err != nil {
-		panic(err)
-	}
+func (t *Traggo) Ping() error {
+	return t.PingContext(context.Background())
+}
 
-	req, err := http.NewRequest(method, t.Url, bytes.NewReader(body))
+func (t *Traggo) PingContext(ctx context.Context) error {
+	r, err := execute[TraggoUser](ctx, t, qCurrentUser, nil)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if r.User == nil {
+		return fmt.Errorf("successfully access traggo, but got no information about user. Check token")
 	}
+	return nil
+}
 
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Cookie", fmt.Sprintf("traggo=%s", t.Token))
+func (t *Traggo) CheckTagsInConfig() error {
+	return t.CheckTagsInConfigContext(context.Background())
+}
 
-	res, err := http.DefaultClient.Do(req)
+func (t *Traggo) CheckTagsInConfigContext(ctx context.Context) error {
+	unknownTags, err := t.MissingTagsContext(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-
-	if res.StatusCode != 200 {
-		c, _ := io.ReadAll(res.Body)
-		fmt.Println(string(c))
-		return fmt.Errorf("command '%s' failed. '%s'", command, res.Status)
+	if len(unknownTags) > 0 {
+		return fmt.Errorf("unknown tags: %s", strings.Join(unknownTags, ","))
 	}
 
-	json.NewDecoder(res.Body).Decode(model)
-
 	return nil
 }
 
-func (t *Traggo) Ping() error {
-	op := Operation{
-		OperationName: "CurrentUser",
-		Query:         "query CurrentUser {\n  user: currentUser {\n    name\n    id\n  }\n}\n",
-	}
-
-	var r TraggoCheckResponse
-	t.Request("CurrentUser", "POST", op, &r)
-	if r.Data.User == nil {
-		return fmt.Errorf("successfully access traggo, but got no information about user. Check token")
-	}
-	return nil
+// MissingTags returns the tag names of the configuration unknown by Traggo
+func (t *Traggo) MissingTags() ([]string, error) {
+	return t.MissingTagsContext(context.Background())
 }
 
-func (t *Traggo) CheckTagsInConfig() error {
-	knownTags := t.GetTags()
+func (t *Traggo) MissingTagsContext(ctx context.Context) ([]string, error) {
+	knownTags, err := t.GetTagsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var unknownTags []string
 	for _, cTag := range t.Tags {
 		if !knownTags.Contain(cTag.TagName) {
 			unknownTags = append(unknownTags, cTag.TagName)
 		}
 	}
-	if len(unknownTags) > 0 {
-		return fmt.Errorf("unknown tags: %s", strings.Join(unknownTags, ","))
-	}
-
-	return nil
+	return unknownTags, nil
 }
 
 func RequestPermanentTokenAndTest(url, login, password string) (string, error) {
-	variables := json.RawMessage(fmt.Sprintf(`{"login": "%s", "password": "%s"}`, login, password))
-	op := Operation{
-		OperationName: "Login",
-		Variables:     &variables,
-		Query:         "mutation Login($name: String!, $pass: String!) {login(username: $name, pass: $pass, deviceName: \"test\", type: NoExpiry, cookie: false) {token user{id, name, admin, __typename}}}",
-	}
-	var body []byte
-	body, err := json.Marshal(op)
-	if err != nil {
-		return "", err
-	}
+	return RequestPermanentTokenAndTestContext(context.Background(), url, login, password)
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
-	if err != nil {
-		return "", err
+func RequestPermanentTokenAndTestContext(ctx context.Context, url, login, password string) (string, error) {
+	variables := struct {
+		Name string `json:"name"`
+		Pass string `json:"pass"`
+	}{
+		Name: login,
+		Pass: password,
 	}
-	req.Header.Add("Content-Type", "application/json")
-	res, err := http.DefaultClient.Do(req)
+	d, err := execute[DataLogin](ctx, NewTraggoSession(config.NewConfig(url, "")), qLogin, variables)
 	if err != nil {
-		return "", err
-	}
-	if res.StatusCode != 200 {
-		return "", errors.New("authentication failure")
+		return "", fmt.Errorf("authentication failure: %w", err)
 	}
-	var d TraggoAuthResponse
-	json.NewDecoder(res.Body).Decode(&d)
 
 	// Test connectivity
-	c := config.NewConfig(url, d.Data.Login.Token)
-	err = NewTraggoSession(c).Ping()
+	c := config.NewConfig(url, d.Login.Token)
+	err = NewTraggoSession(c).PingContext(ctx)
 	if err != nil {
 		return "", err
 	}
-	return d.Data.Login.Token, nil
+	return d.Login.Token, nil
 }
 
 type UserSettingsData struct {
@@ -154,44 +163,50 @@ type UserSettingsData struct {
 	DateTimeInputStyle string `json:"dateTimeInputStyle"`
 }
 
-type UserSettingsRoot struct {
-	Data UserSettings `json:"data"`
-}
-
 type UserSettings struct {
 	UserSettings UserSettingsData `json:"userSettings"`
 }
 
-func (t *Traggo) GetSettings() {
-	op := Operation{
-		OperationName: "Settings",
-		Query:         "query Settings {\n  userSettings {\n    theme\n    dateLocale\n    firstDayOfTheWeek\n    dateTimeInputStyle}\n}\n",
-	}
-	var body []byte
-	body, err := json.Marshal(op)
+func (t *Traggo) GetSettings() (UserSettingsData, error) {
+	return t.GetSettingsContext(context.Background())
+}
+
+func (t *Traggo) GetSettingsContext(ctx context.Context) (UserSettingsData, error) {
+	r, err := execute[UserSettings](ctx, t, qSettings, nil)
 	if err != nil {
-		panic(err)
+		return UserSettingsData{}, err
 	}
+	return r.UserSettings, nil
+}
 
-	req, err := http.NewRequest("POST", t.Url, bytes.NewReader(body))
-	if err != nil {
-		log.Fatal(err)
+type setUserSettingsData struct {
+	UserSettings UserSettingsData `json:"setUserSettings"`
+}
+
+// SetSettings replaces the user settings
+func (t *Traggo) SetSettings(settings UserSettingsData) (UserSettingsData, error) {
+	return t.SetSettingsContext(context.Background(), settings)
+}
+
+func (t *Traggo) SetSettingsContext(ctx context.Context, settings UserSettingsData) (UserSettingsData, error) {
+	variables := struct {
+		Settings UserSettingsData `json:"settings"`
+	}{
+		Settings: settings,
 	}
-	req.Header.Add("Content-Type", "application/json")
-	res, err := http.DefaultClient.Do(req)
+	r, err := execute[setUserSettingsData](ctx, t, qSetSettings, variables)
 	if err != nil {
-		log.Fatal(err)
-	}
-	if res.StatusCode != 200 {
-		log.Fatal("GetSettings failure")
+		return UserSettingsData{}, err
 	}
-	var r UserSettingsRoot
-	json.NewDecoder(res.Body).Decode(&r)
+	return r.UserSettings, nil
+}
 
-	fmt.Println("User settings:")
-	fmt.Println("--------------")
-	fmt.Printf("  - dateLocale: %s\n", r.Data.UserSettings.DateLocale)
-	fmt.Printf("  - theme: %s\n", r.Data.UserSettings.Theme)
-	fmt.Printf("  - firstDayOfTheWeek: %s\n", r.Data.UserSettings.FirstDayOfTheWeek)
-	fmt.Printf("  - dateTimeInputStyle: %s\n", r.Data.UserSettings.DateTimeInputStyle)
+func (u UserSettingsData) String() string {
+	s := "User settings:\n"
+	s += "--------------\n"
+	s += fmt.Sprintf("  - dateLocale: %s\n", u.DateLocale)
+	s += fmt.Sprintf("  - theme: %s\n", u.Theme)
+	s += fmt.Sprintf("  - firstDayOfTheWeek: %s\n", u.FirstDayOfTheWeek)
+	s += fmt.Sprintf("  - dateTimeInputStyle: %s", u.DateTimeInputStyle)
+	return s
 }
@@ -0,0 +1,153 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/kalidor/traggo_cli/config"
+)
+
+type StatsRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// StatsQuery selects what Traggo sums up: time spans in Ranges, grouped by
+// the values of Tags. Time spans with one of ExcludeTags are ignored, and
+// time spans without all RequireTags too.
+type StatsQuery struct {
+	Ranges      []StatsRange `json:"ranges"`
+	Tags        []string     `json:"tags"`
+	ExcludeTags []Tag        `json:"excludeTags,omitempty"`
+	RequireTags []Tag        `json:"requireTags,omitempty"`
+}
+
+// StatsEntry is the time spent on one tag value
+type StatsEntry struct {
+	Key                string  `json:"key"`
+	Value              string  `json:"value"`
+	TimeSpendInSeconds float64 `json:"timeSpendInSeconds"`
+}
+
+// RangedStats are the entries of one range of a StatsQuery
+type RangedStats struct {
+	Start   time.Time    `json:"start"`
+	End     time.Time    `json:"end"`
+	Entries []StatsEntry `json:"entries"`
+}
+
+type StatsList []RangedStats
+
+type statsData struct {
+	Stats StatsList `json:"stats"`
+}
+
+// StatsRanges cuts [start, end] by interval (IntervalNone, IntervalDay,
+// IntervalWeek or IntervalMonth). Periods are cut in local time.
+func StatsRanges(start, end time.Time, interval string) ([]StatsRange, error) {
+	switch interval {
+	case IntervalNone:
+		return []StatsRange{{Start: start, End: end}}, nil
+	case IntervalDay, IntervalWeek, IntervalMonth:
+	default:
+		return nil, fmt.Errorf("invalid interval '%s', expected day, week or month", interval)
+	}
+	var ranges []StatsRange
+	from := start.Local()
+	for from.Before(end) {
+		to := periodEnd(periodStart(from, interval), interval)
+		if to.After(end) {
+			to = end.Local()
+		}
+		ranges = append(ranges, StatsRange{Start: from, End: to})
+		from = to
+	}
+	return ranges, nil
+}
+
+// Stats asks Traggo to sum up the time spent, without downloading time spans
+func (t *Traggo) Stats(q StatsQuery) (StatsList, error) {
+	return t.StatsContext(context.Background(), q)
+}
+
+func (t *Traggo) StatsContext(ctx context.Context, q StatsQuery) (StatsList, error) {
+	d, err := execute[statsData](ctx, t, qStats, q)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range d.Stats {
+		sort.SliceStable(r.Entries, func(i, j int) bool {
+			return r.Entries[i].TimeSpendInSeconds > r.Entries[j].TimeSpendInSeconds
+		})
+	}
+	return d.Stats, nil
+}
+
+// Header names the columns of Rows
+func (s StatsList) Header() []string {
+	return []string{"start", "end", "key", "value", "duration"}
+}
+
+// Rows returns one row per entry, the duration is in seconds
+func (s StatsList) Rows() [][]string {
+	var rows [][]string
+	for _, r := range s {
+		for _, e := range r.Entries {
+			rows = append(rows, []string{
+				r.Start.Format(time.RFC3339),
+				r.End.Format(time.RFC3339),
+				e.Key,
+				e.Value,
+				fmt.Sprintf("%.0f", e.TimeSpendInSeconds),
+			})
+		}
+	}
+	return rows
+}
+
+func (s StatsList) PreparePretty(colors config.ColorsDef) string {
+	var rows [][]string
+	for _, r := range s {
+		for _, e := range r.Entries {
+			d := time.Duration(e.TimeSpendInSeconds) * time.Second
+			rows = append(rows, []string{
+				r.Start.Format(time.DateTime),
+				r.End.Format(time.DateTime),
+				fmt.Sprintf("%s:%s", e.Key, e.Value),
+				d.String(),
+			})
+		}
+	}
+	ta := table.New().
+		BorderStyle(BorderStyle).
+		Headers("Start", "End", "Tag", "Time").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			var style lipgloss.Style
+			switch {
+			case row == table.HeaderRow:
+				return baseStyle.Foreground(colors.Table.HeaderStyle).Bold(true)
+			case row%2 == 0:
+				style = CellStyle.Foreground(colors.Table.EvenStyle)
+			default:
+				style = CellStyle.Foreground(colors.Table.OddStyle)
+			}
+			switch col {
+			case 0, 1:
+				style = style.Width(23)
+			case 2:
+				style = style.Width(30)
+				for _, c := range colors.Tags {
+					if rows[row][2] == fmt.Sprintf("%s:%s", c.TagName, c.TagValue) {
+						return style.Foreground(c.Color)
+					}
+				}
+			}
+			return style
+		}).
+		Rows(rows...)
+	return ta.String()
+}
@@ -0,0 +1,195 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+)
+
+// StatusCache is a short-lived local copy of the running timers, small
+// enough to be read on each render of a shell prompt. It is refreshed by
+// every Trackers query and outdated by every mutation.
+type StatusCache struct {
+	Path    string        // cache file
+	TTL     time.Duration // cached timers are used without asking Traggo during this delay
+	Timeout time.Duration // maximum duration of the request refreshing the cache, without retry
+
+	mu sync.Mutex
+}
+
+type statusData struct {
+	Timers  []TimerTask `json:"timers"`
+	Fetched time.Time   `json:"fetched"`
+}
+
+func NewStatusCache(path string, ttl, timeout time.Duration) *StatusCache {
+	if ttl <= 0 {
+		ttl = config.DefaultStatusTTL
+	}
+	if timeout <= 0 {
+		timeout = config.DefaultStatusTimeout
+	}
+	return &StatusCache{Path: path, TTL: ttl, Timeout: timeout}
+}
+
+// get returns the cached timers, false if they are missing. fresh tells if
+// they are younger than TTL.
+func (c *StatusCache) get() (timers TimersData, fresh bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, err := os.ReadFile(c.Path)
+	if err != nil {
+		return TimersData{}, false, false
+	}
+	var data statusData
+	if json.Unmarshal(d, &data) != nil {
+		return TimersData{}, false, false
+	}
+	age := TimeNow().Sub(data.Fetched)
+	return TimersData{Timers: data.Timers}, !data.Fetched.IsZero() && age >= 0 && age < c.TTL, true
+}
+
+// put stores the timers just returned by Traggo. Failures are ignored,
+// the next status asks Traggo again.
+func (c *StatusCache) put(timers TimersData) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, err := json.Marshal(statusData{Timers: timers.Timers, Fetched: TimeNow()})
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(c.Path), 0o770)
+	}
+	if err == nil {
+		err = os.WriteFile(c.Path, d, 0o600)
+	}
+	if err != nil {
+		os.Remove(c.Path)
+	}
+}
+
+// Invalidate makes the next status ask Traggo for the running timers. The
+// cached ones are kept as the last known timers, used if Traggo fails.
+func (c *StatusCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, err := os.ReadFile(c.Path)
+	if err != nil {
+		return
+	}
+	var data statusData
+	err = json.Unmarshal(d, &data)
+	if err == nil {
+		data.Fetched = time.Time{}
+		d, err = json.Marshal(data)
+	}
+	if err == nil {
+		err = os.WriteFile(c.Path, d, 0o600)
+	}
+	if err != nil {
+		os.Remove(c.Path)
+	}
+}
+
+// CurrentTimers returns the running timers, from the status cache if it is
+// recent enough. Otherwise Traggo is asked once, within the timeout of the
+// status cache, so a shell prompt is never blocked for long. If this fails,
+// the last known timers are returned as stale, without error.
+func (t *Traggo) CurrentTimers() (timers TimersData, stale bool, err error) {
+	return t.CurrentTimersContext(context.Background())
+}
+
+func (t *Traggo) CurrentTimersContext(ctx context.Context) (timers TimersData, stale bool, err error) {
+	if t.Status == nil {
+		timers, err = t.ListCurrentTasksContext(ctx)
+		return timers, false, err
+	}
+	cached, fresh, ok := t.Status.get()
+	if fresh {
+		return cached, false, nil
+	}
+	quick := *t
+	quick.Timeout = t.Status.Timeout
+	quick.Retries = 0
+	timers, err = quick.ListCurrentTasksContext(ctx)
+	if err != nil && ok && ctx.Err() == nil {
+		return cached, true, nil
+	}
+	return timers, false, err
+}
+
+// Status describes the running timers for shell prompts and status bars.
+// Its fields are the ones of the status templates.
+type Status struct {
+	Running  bool   // a timer is running
+	Count    int    // number of running timers
+	Id       int    // latest started timer, described by the following fields
+	Tags     string // its tags, key:value space separated
+	Values   string // values of its tags, space separated
+	Note     string
+	Start    time.Time
+	Duration time.Duration // running duration
+	Elapsed  string        // running duration in short, 1h05m
+	Timers   []TimerTask   // all the running timers
+	Stale    bool          // Traggo failed, these are the last known timers
+}
+
+// NewStatus describes timers, at TimeNow
+func NewStatus(timers TimersData) Status {
+	timer, ok := timers.Last()
+	if !ok {
+		return Status{}
+	}
+	values := make([]string, len(timer.Tags))
+	for i, tag := range timer.Tags {
+		values[i] = tag.Value
+	}
+	duration := max(0, TimeNow().Sub(timer.Start))
+	return Status{
+		Running:  true,
+		Count:    len(timers.Timers),
+		Id:       timer.Id,
+		Tags:     strings.Join(timer.ExportTags(), " "),
+		Values:   strings.Join(values, " "),
+		Note:     timer.Note,
+		Start:    timer.Start,
+		Duration: duration,
+		Elapsed:  FormatElapsed(duration),
+		Timers:   timers.Timers,
+	}
+}
+
+// FormatElapsed writes d in short, in minutes: 5m, 1h05m
+func FormatElapsed(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
+
+// Render executes the text/template tmpl on s
+func (s Status) Render(tmpl string) (string, error) {
+	tpl, err := template.New("status").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid status template: %w", err)
+	}
+	var b strings.Builder
+	err = tpl.Execute(&b, s)
+	if err != nil {
+		return "", fmt.Errorf("invalid status template: %w", err)
+	}
+	return b.String(), nil
+}
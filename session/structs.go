@@ -50,17 +50,20 @@ type GenericTask interface {
 	GetStart() time.Time
 	GetStartString() string
 	GetStopString() string
+	HasTag(tagName, tagValue string) bool
+	Record() Record
 	PreparePretty(config.ColorsDef) string
 	Type() taskType
-	Update(start, stop, note string, tags []string) GenericTask
+	Update(start, stop, note string, tags []string) (GenericTask, error)
 }
 
 type Error struct {
-	Message string   `json:"message"`
-	Path    []string `json:"path"`
+	Message string `json:"message"`
+	Path    []any  `json:"path"`
 }
 
 type CursorRequest struct {
+	StartId  int `json:"startId,omitempty"` // keeps pages stable while new time spans are created
 	Offset   int `json:"offset"`
 	PageSize int `json:"pageSize,omitempty"`
 }
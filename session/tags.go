@@ -1,72 +1,207 @@
 package session
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
+	"hash/fnv"
+	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/kalidor/traggo_cli/config"
 )
 
-type tag struct {
+// TagDefinition is a tag name known by Traggo
+type TagDefinition struct {
 	Key    string `json:"key"`
+	Color  string `json:"color"`
 	Usages int    `json:"usages"`
 }
-type tags []tag
+type TagDefinitions []TagDefinition
 
 type datatags struct {
-	Tags tags `json:"tags"`
+	Tags TagDefinitions `json:"tags"`
+}
+
+type removeTagData struct {
+	RemoveTag *struct {
+		Key string `json:"key"`
+	} `json:"removeTag"`
+}
+
+type createTagData struct {
+	CreateTag TagDefinition `json:"createTag"`
+}
+
+type updateTagData struct {
+	UpdateTag TagDefinition `json:"updateTag"`
+}
+
+// tagColors are proposed for new tags, like Traggo web UI does
+var tagColors = []string{
+	"#f44336", "#e91e63", "#9c27b0", "#673ab7", "#3f51b5", "#2196f3", "#03a9f4", "#00bcd4",
+	"#009688", "#4caf50", "#8bc34a", "#cddc39", "#ffc107", "#ff9800", "#ff5722", "#795548",
 }
-type rootTags struct {
-	Data datatags `json:"data"`
+
+var colorRe = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// TagColor returns a color for a new tag, always the same for a given name
+func TagColor(key string) string {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return tagColors[h.Sum32()%uint32(len(tagColors))]
 }
 
-func (t tags) Contain(tagName string) bool {
+// CheckColor tells if color is written #rrggbb, as expected by Traggo
+func CheckColor(color string) error {
+	if !colorRe.MatchString(color) {
+		return fmt.Errorf("invalid color '%s', expected #rrggbb", color)
+	}
+	return nil
+}
+
+func (t TagDefinitions) Contain(tagName string) bool {
+	_, ok := t.Get(tagName)
+	return ok
+}
+
+// Has tells if tagName is known, with the same case
+func (t TagDefinitions) Has(tagName string) bool {
 	for _, tag := range t {
-		if strings.EqualFold(tag.Key, tagName) {
+		if tag.Key == tagName {
 			return true
 		}
 	}
 	return false
 }
 
-func (t *Traggo) GetTags() tags {
-	op := Operation{
-		OperationName: "Tags",
-		Query:         "query Tags {\n  tags {\n    key\n    usages\n}\n}",
+// Get returns the definition of tagName
+func (t TagDefinitions) Get(tagName string) (TagDefinition, bool) {
+	for _, tag := range t {
+		if strings.EqualFold(tag.Key, tagName) {
+			return tag, true
+		}
+	}
+	return TagDefinition{}, false
+}
+
+// GetTags returns the tags known by Traggo, sorted by name
+func (t *Traggo) GetTags() (TagDefinitions, error) {
+	return t.GetTagsContext(context.Background())
+}
+
+func (t *Traggo) GetTagsContext(ctx context.Context) (TagDefinitions, error) {
+	d, err := execute[datatags](ctx, t, qTags, nil)
+	if err != nil {
+		return nil, err
 	}
+	sort.Slice(d.Tags, func(i, j int) bool {
+		return d.Tags[i].Key < d.Tags[j].Key
+	})
+	return d.Tags, nil
+}
+
+func (t *Traggo) RemoveTag(tagName string) error {
+	return t.RemoveTagContext(context.Background(), tagName)
+}
 
-	// Parse http.Response Boby as JSON and display it
-	var d rootTags
-	err := t.Request(
-		"GetTags",
-		"POST",
-		op,
-		&d,
-	)
+func (t *Traggo) RemoveTagContext(ctx context.Context, tagName string) error {
+	variables := struct {
+		Key string `json:"key"`
+	}{
+		Key: tagName,
+	}
+	d, err := execute[removeTagData](ctx, t, qRemoveTag, variables)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if d.RemoveTag == nil {
+		return fmt.Errorf("unknown tag '%s'", tagName)
 	}
-	return d.Data.Tags
+	// cached time spans may still have the tag
+	t.Cache.Clear()
+	return nil
+}
 
+// CreateTag defines a new tag name with its color (#rrggbb)
+func (t *Traggo) CreateTag(key, color string) (TagDefinition, error) {
+	return t.CreateTagContext(context.Background(), key, color)
 }
 
-func (t *Traggo) RemoveTag(tagName string) {
-	variables := json.RawMessage(fmt.Sprintf(`{"key": "%s"}`, tagName))
-	op := Operation{
-		OperationName: "RemoveTag",
-		Variables:     &variables,
-		Query:         "mutation RemoveTag($key: String!) {\n  removeTag(key: $key) {\n    color\n    key\n  }\n}",
+func (t *Traggo) CreateTagContext(ctx context.Context, key, color string) (TagDefinition, error) {
+	variables := struct {
+		Key   string `json:"key"`
+		Color string `json:"color"`
+	}{
+		Key:   key,
+		Color: color,
 	}
+	d, err := execute[createTagData](ctx, t, qCreateTag, variables)
+	if err != nil {
+		return TagDefinition{}, err
+	}
+	return d.CreateTag, nil
+}
 
-	// Parse http.Response Boby as JSON and display it
-	var d rootTags
-	err := t.Request(
-		"GetTags",
-		"POST",
-		op,
-		&d,
-	)
+// UpdateTag renames tag key to newKey (kept if empty) and changes its color.
+// Time spans using the tag are renamed by Traggo.
+func (t *Traggo) UpdateTag(key, newKey, color string) (TagDefinition, error) {
+	return t.UpdateTagContext(context.Background(), key, newKey, color)
+}
+
+func (t *Traggo) UpdateTagContext(ctx context.Context, key, newKey, color string) (TagDefinition, error) {
+	variables := struct {
+		Key    string `json:"key"`
+		NewKey string `json:"newKey,omitempty"`
+		Color  string `json:"color"`
+	}{
+		Key:    key,
+		NewKey: newKey,
+		Color:  color,
+	}
+	d, err := execute[updateTagData](ctx, t, qUpdateTag, variables)
 	if err != nil {
-		log.Fatal(err)
+		return TagDefinition{}, err
 	}
+	if newKey != "" && newKey != key {
+		// cached time spans still have the old name
+		t.Cache.Clear()
+	}
+	return d.UpdateTag, nil
+}
+
+// Header names the columns of Rows
+func (t TagDefinitions) Header() []string {
+	return []string{"key", "color", "usages"}
+}
+
+func (t TagDefinitions) Rows() [][]string {
+	rows := make([][]string, len(t))
+	for i, tag := range t {
+		rows[i] = []string{tag.Key, tag.Color, fmt.Sprintf("%d", tag.Usages)}
+	}
+	return rows
+}
+
+func (t TagDefinitions) PreparePretty(colors config.ColorsDef) string {
+	ta := table.New().
+		BorderStyle(BorderStyle).
+		Headers("Key", "Color", "Usages").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return baseStyle.Foreground(colors.Table.HeaderStyle).Bold(true)
+			case col == 1:
+				// show the color itself
+				return CellStyle.Foreground(lipgloss.Color(t[row].Color))
+			case row%2 == 0:
+				return CellStyle.Foreground(colors.Table.EvenStyle)
+			default:
+				return CellStyle.Foreground(colors.Table.OddStyle)
+			}
+		}).
+		Rows(t.Rows()...)
+	return ta.String()
 }
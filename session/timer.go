@@ -25,11 +25,6 @@ type TimersData struct {
 	Timers []TimerTask `json:"timers"`
 }
 
-type TimerTasks struct {
-	Data   TimersData `json:"data"`
-	Errors []Error    `json:"errors"`
-}
-
 func (t TimerTask) Export() []string {
 	return []string{
 		fmt.Sprintf("%d", t.Id),
@@ -47,6 +42,17 @@ func (t TimerTask) ExportTags() []string {
 	return r
 }
 
+// HasTag tells if the task is tagged with tagName:tagValue.
+// Any value of tagName matches an empty tagValue.
+func (t TimerTask) HasTag(tagName, tagValue string) bool {
+	for _, tag := range t.Tags {
+		if tag.Key == tagName && (tagValue == "" || tag.Value == tagValue) {
+			return true
+		}
+	}
+	return false
+}
+
 func (t TimerTask) GetId() int {
 	return t.Id
 }
@@ -71,6 +77,31 @@ func (t TimersData) IsEmpty() bool {
 	return len(t.Timers) == 0
 }
 
+// Filter returns the timers matching filter
+func (t TimersData) Filter(filter TaskFilter) TimersData {
+	var found TimersData
+	for _, timer := range t.Timers {
+		if filter.Match(timer) {
+			found.Timers = append(found.Timers, timer)
+		}
+	}
+	return found
+}
+
+// Last returns the most recently started timer, false if none runs
+func (t TimersData) Last() (TimerTask, bool) {
+	if t.IsEmpty() {
+		return TimerTask{}, false
+	}
+	last := t.Timers[0]
+	for _, timer := range t.Timers[1:] {
+		if timer.Start.After(last.Start) {
+			last = timer
+		}
+	}
+	return last, true
+}
+
 func (t TimerTask) PreparePretty(colors config.ColorsDef) string {
 	var l TimersData
 	l.Timers = append(l.Timers, t)
@@ -173,8 +204,11 @@ func (t TimerTask) Type() taskType {
 
 // Update current TimerTask.
 // stop is not used since this is a current task
-func (t TimerTask) Update(start, stop, note string, tagsString []string) GenericTask {
-	s, _ := utils.StrToTime(start, time.DateTime)
+func (t TimerTask) Update(start, stop, note string, tagsString []string) (GenericTask, error) {
+	s, err := utils.ParseDate(start, TimeNow())
+	if err != nil {
+		return nil, err
+	}
 
 	var tags []Tag
 	for _, tag := range tagsString {
@@ -188,6 +222,6 @@ func (t TimerTask) Update(start, stop, note string, tagsString []string) Generic
 		Start: s,
 		Tags:  tags,
 		Note:  note,
-	}
+	}, nil
 
 }
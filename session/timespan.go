@@ -33,18 +33,6 @@ type TimeSpans struct {
 type TimeSpansData struct {
 	TimeSpans TimeSpans `json:"timeSpans"`
 }
-type TimeSpanRoot struct {
-	Data TimeSpansData `json:"data"`
-}
-
-type TimeSpanData struct {
-	Timers []TimeSpanTask `json:"timers"`
-}
-
-type TimeSpanTasks struct {
-	Data   TimeSpanData `json:"data"`
-	Errors []Error      `json:"errors"`
-}
 
 func (t TimeSpanTask) Export() []string {
 	duration := t.End.Sub(t.Start)
@@ -189,9 +177,15 @@ func (t TimeSpanTask) Type() taskType {
 
 // Update current TimerTask.
 // stop is not used since this is a current task
-func (t TimeSpanTask) Update(start, stop, note string, tagsString []string) GenericTask {
-	_start, _ := utils.StrToTime(start, time.DateTime)
-	_end, _ := utils.StrToTime(stop, time.DateTime)
+func (t TimeSpanTask) Update(start, stop, note string, tagsString []string) (GenericTask, error) {
+	_start, err := utils.ParseDate(start, TimeNow())
+	if err != nil {
+		return nil, err
+	}
+	_end, err := utils.ParseDate(stop, TimeNow())
+	if err != nil {
+		return nil, err
+	}
 
 	var tags []Tag
 	for _, tag := range tagsString {
@@ -208,5 +202,5 @@ func (t TimeSpanTask) Update(start, stop, note string, tagsString []string) Gene
 			Note:  note,
 		},
 		End: _end,
-	}
+	}, nil
 }
@@ -1,11 +1,8 @@
 package session
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
-	"net/http"
 	"time"
 )
 
@@ -18,45 +15,31 @@ type RootVersion struct {
 	Version Version `json:"version"`
 }
 
-type DataRootVersion struct {
-	Data RootVersion `json:"data"`
+func (t *Traggo) GetVersion() (Version, error) {
+	return t.GetVersionContext(context.Background())
 }
 
-func (t *Traggo) GetVersion() Version {
-	op := Operation{
-		OperationName: "Version",
-		Query:         "query Version {  version {    name    commit    buildDate    __typename  }}",
-	}
-	var body []byte
-	body, err := json.Marshal(op)
-	if err != nil {
-		panic(err)
-	}
-	req, err := http.NewRequest("POST", t.Url, bytes.NewReader(body))
+func (t *Traggo) GetVersionContext(ctx context.Context) (Version, error) {
+	d, err := execute[RootVersion](ctx, t, qVersion, nil)
 	if err != nil {
-		log.Fatal(err)
+		return Version{}, err
 	}
-	req.Header.Add("Content-Type", "application/json")
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if res.StatusCode != 200 {
-		fmt.Println("Requesting version failure")
-		fmt.Println(res.Status)
-	}
-	var d DataRootVersion
-	json.NewDecoder(res.Body).Decode(&d)
 
 	//TODO: align output
 	// Version: Name: 0.7.1
 	// Commit:4aa48b385abb1728e46881964ce90a420a25f590
 	// Build date:2025-04-28T15:21:13Z
-	return d.Data.Version
+	return d.Version, nil
+}
+
+func (t *Traggo) Version() (string, error) {
+	return t.VersionContext(context.Background())
 }
 
-func (t *Traggo) Version() string {
-	version := t.GetVersion()
-	return fmt.Sprintf("Name: %s\nCommit: %s\nBuild date: %s\n", version.Name, version.Commit, version.BuildDate.Format(time.RFC3339))
+func (t *Traggo) VersionContext(ctx context.Context) (string, error) {
+	version, err := t.GetVersionContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Name: %s\nCommit: %s\nBuild date: %s\n", version.Name, version.Commit, version.BuildDate.Format(time.RFC3339)), nil
 }
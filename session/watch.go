@@ -0,0 +1,215 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reasons of a WatchAlert
+const (
+	ReasonMaxDuration = "max-duration" // the timer runs for too long
+	ReasonEndOfDay    = "end-of-day"   // the timer still runs after the end of the day
+)
+
+// WatchAlert is a timer which has probably been forgotten
+type WatchAlert struct {
+	Timer  TimerTask
+	Reason string
+	At     time.Time // when the limit was crossed, taken as the last activity
+}
+
+func (a WatchAlert) String() string {
+	return fmt.Sprintf("timer %d [%s] started %s: %s reached at %s", a.Timer.Id, strings.Join(a.Timer.ExportTags(), ","), a.Timer.Start.Local().Format(time.DateTime), a.Reason, a.At.Local().Format(time.DateTime))
+}
+
+// Notifier tells the user about a forgotten timer
+type Notifier interface {
+	Notify(ctx context.Context, alert WatchAlert) error
+}
+
+// NotifierFunc is a function used as a Notifier
+type NotifierFunc func(ctx context.Context, alert WatchAlert) error
+
+func (f NotifierFunc) Notify(ctx context.Context, alert WatchAlert) error {
+	return f(ctx, alert)
+}
+
+// Watcher looks for forgotten timers: running longer than MaxDuration, or
+// still running at EndOfDay. Each timer is reported to the notifiers, then
+// stopped at the time of the alert if AutoStop is set. This is done once,
+// unless it fails: the timer is reported again by the next check.
+// The clock is TimeNow.
+type Watcher struct {
+	Session     *Traggo
+	MaxDuration time.Duration // no limit if 0
+	EndOfDay    time.Duration // from midnight, 24h for midnight at the end of the day, no limit if 0
+	Notifiers   []Notifier
+	AutoStop    bool
+	// StatePath, if not empty, is the file keeping the reported timers, so
+	// they are reported once across runs
+	StatePath string
+	// Report, if not nil, is called with each alert and the error of its
+	// handling, and with the errors of the polls with an empty alert
+	Report func(alert WatchAlert, err error)
+
+	alerted map[int]bool // ids of the timers already reported
+}
+
+type watchState struct {
+	Alerted []int `json:"alerted"`
+}
+
+// loadState reads the reported timers once. An unreadable file is an empty state.
+func (w *Watcher) loadState() {
+	if w.alerted != nil {
+		return
+	}
+	w.alerted = map[int]bool{}
+	if w.StatePath == "" {
+		return
+	}
+	d, err := os.ReadFile(w.StatePath)
+	if err != nil {
+		return
+	}
+	var state watchState
+	if json.Unmarshal(d, &state) == nil {
+		for _, id := range state.Alerted {
+			w.alerted[id] = true
+		}
+	}
+}
+
+// saveState writes the reported timers
+func (w *Watcher) saveState() error {
+	if w.StatePath == "" {
+		return nil
+	}
+	state := watchState{Alerted: []int{}}
+	for id := range w.alerted {
+		state.Alerted = append(state.Alerted, id)
+	}
+	sort.Ints(state.Alerted)
+	d, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(w.StatePath), 0o770)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(w.StatePath, d, 0o600)
+}
+
+// alert returns the first limit crossed by timer, false if none is
+func (w *Watcher) alert(timer TimerTask, now time.Time) (WatchAlert, bool) {
+	var found WatchAlert
+	if w.MaxDuration > 0 {
+		at := timer.Start.Add(w.MaxDuration)
+		if !at.After(now) {
+			found = WatchAlert{Timer: timer, Reason: ReasonMaxDuration, At: at}
+		}
+	}
+	if w.EndOfDay > 0 {
+		start := timer.Start.Local()
+		at := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local).Add(w.EndOfDay)
+		if !at.After(start) {
+			at = at.AddDate(0, 0, 1)
+		}
+		if !at.After(now) && (found.Reason == "" || at.Before(found.At)) {
+			found = WatchAlert{Timer: timer, Reason: ReasonEndOfDay, At: at}
+		}
+	}
+	return found, found.Reason != ""
+}
+
+// Check polls the running timers once, and handles the new alerts. The
+// errors of their handling are returned too, joined.
+func (w *Watcher) Check(ctx context.Context) ([]WatchAlert, error) {
+	alerts, handleErr, err := w.check(ctx)
+	return alerts, errors.Join(err, handleErr)
+}
+
+// check is Check returning the errors of the alerts apart from the errors
+// of the poll
+func (w *Watcher) check(ctx context.Context) ([]WatchAlert, error, error) {
+	timers, err := w.Session.ListCurrentTasksContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	w.loadState()
+	now := TimeNow()
+	running := map[int]bool{}
+	var alerts []WatchAlert
+	var handleErrs []error
+	for _, timer := range timers.Timers {
+		running[timer.Id] = true
+		if w.alerted[timer.Id] {
+			continue
+		}
+		alert, ok := w.alert(timer, now)
+		if !ok {
+			continue
+		}
+		alerts = append(alerts, alert)
+		err := w.handle(ctx, alert)
+		if w.Report != nil {
+			w.Report(alert, err)
+		}
+		if err != nil {
+			handleErrs = append(handleErrs, fmt.Errorf("timer %d: %w", timer.Id, err))
+			continue
+		}
+		w.alerted[timer.Id] = true
+	}
+	// stopped timers are forgotten
+	for id := range w.alerted {
+		if !running[id] {
+			delete(w.alerted, id)
+		}
+	}
+	return alerts, errors.Join(handleErrs...), w.saveState()
+}
+
+// handle notifies alert then stops its timer, whatever the notifiers fail.
+// A stop queued while Traggo is unreachable is not a failure.
+func (w *Watcher) handle(ctx context.Context, alert WatchAlert) error {
+	var errs []error
+	for _, n := range w.Notifiers {
+		errs = append(errs, n.Notify(ctx, alert))
+	}
+	if w.AutoStop {
+		_, err := w.Session.StopAtContext(ctx, []int{alert.Timer.Id}, alert.At)
+		if errors.Is(err, ErrQueued) {
+			err = nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Watch checks the running timers every interval until ctx is done.
+// Failing polls, Traggo being unreachable for example, do not stop watching.
+func (w *Watcher) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// failing alerts are already reported
+		_, _, err := w.check(ctx)
+		if err != nil && w.Report != nil && ctx.Err() == nil {
+			w.Report(WatchAlert{}, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
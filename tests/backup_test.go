@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+const backupAccount = `{
+	"Version": {"version": {"name": "0.7.1", "commit": "4aa48b3", "buildDate": "2025-04-28T15:21:13Z"}},
+	"Tags": {"tags": [{"key": "project", "color": "#ff0000", "usages": 2}, {"key": "type", "color": "#00ff00", "usages": 1}]},
+	"Settings": {"userSettings": {"theme": "GruvboxDark", "dateLocale": "German", "firstDayOfTheWeek": "Monday", "dateTimeInputStyle": "Fancy"}},
+	"Dashboards": {"dashboards": [{"id": 4, "name": "Work", "ranges": [{"id": 9, "name": "Last week", "editable": true, "range": {"from": "now-1w", "to": "now"}}],
+		"items": [{"id": 12, "title": "Projects", "total": true, "entryType": "PieChart",
+			"pos": {"desktop": {"x": 0, "y": 0, "w": 4, "h": 2, "minW": 2, "minH": 2}, "mobile": {"x": 0, "y": 0, "w": 2, "h": 2, "minW": 2, "minH": 2}},
+			"statsSelection": {"interval": "Daily", "tags": ["project"], "excludeTags": null, "includeTags": null, "range": null, "rangeId": 9}}]}]},
+	"Trackers": {"timers": [{"id": 30, "start": "2025-12-02T09:00:00+01:00", "tags": [{"key": "project", "value": "cli"}], "note": "running"}]},
+	"TimeSpans": {"timeSpans": {"cursor": {"hasMore": false, "startId": 20, "offset": 2, "pageSize": 100},
+		"timeSpans": [
+			{"id": 20, "start": "2025-12-01T14:00:00+01:00", "end": "2025-12-01T15:00:00+01:00", "tags": [{"key": "project", "value": "web"}], "note": "second"},
+			{"id": 10, "start": "2025-12-01T09:00:00+01:00", "end": "2025-12-01T10:00:00+01:00", "tags": [{"key": "type", "value": "dev"}], "note": "first"}]}}
+}`
+
+// restoreServer is an empty account recording the received mutations
+type restoreServer struct {
+	t      *testing.T
+	nextId int
+	ops    []string
+	vars   []map[string]any
+}
+
+func (s *restoreServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var op struct {
+		OperationName string         `json:"operationName"`
+		Variables     map[string]any `json:"variables"`
+	}
+	json.NewDecoder(r.Body).Decode(&op)
+	s.nextId++
+	data := map[string]any{}
+	switch op.OperationName {
+	case "Trackers":
+		data["timers"] = []any{}
+	case "TimeSpans":
+		data["timeSpans"] = map[string]any{"timeSpans": []any{}, "cursor": map[string]any{"hasMore": false}}
+	case "Tags":
+		tags := []any{map[string]any{"key": "type", "color": "#0000ff"}}
+		for _, tag := range s.find("CreateTag") {
+			tags = append(tags, tag)
+		}
+		data["tags"] = tags
+	case "CreateTag", "SetUserSettings":
+		data["createTag"] = op.Variables
+	case "CreateTimeSpan", "StartTimer":
+		data["createTimeSpan"] = map[string]any{"id": s.nextId}
+	case "CreateDashboard":
+		data["createDashboard"] = map[string]any{"id": s.nextId}
+	case "AddDashboardRange":
+		data["addDashboardRange"] = map[string]any{"id": s.nextId}
+	case "AddDashboardEntry":
+		data["addDashboardEntry"] = map[string]any{"id": s.nextId}
+	default:
+		s.t.Errorf("Unexpected operation %s", op.OperationName)
+	}
+	s.ops = append(s.ops, op.OperationName)
+	s.vars = append(s.vars, op.Variables)
+	json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func (s *restoreServer) find(name string) []map[string]any {
+	var found []map[string]any
+	for i, op := range s.ops {
+		if op == name {
+			found = append(found, s.vars[i])
+		}
+	}
+	return found
+}
+
+func TestBackupRestore(t *testing.T) {
+	var account map[string]json.RawMessage
+	err := json.Unmarshal([]byte(backupAccount), &account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var op struct {
+			OperationName string `json:"operationName"`
+		}
+		json.NewDecoder(r.Body).Decode(&op)
+		w.Write([]byte(`{"data":` + string(account[op.OperationName]) + `}`))
+	}))
+	defer source.Close()
+
+	archive, err := session.NewTraggoSession(config.NewConfigToken(source.URL, TOKEN)).Backup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = archive.Write(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err = session.ReadArchive(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archive.Version != session.ArchiveVersion || archive.Traggo != "0.7.1" || len(archive.TimeSpans) != 2 ||
+		len(archive.Timers) != 1 || len(archive.Tags) != 2 || archive.Tags[0].Color != "#ff0000" ||
+		archive.Settings.Theme != "GruvboxDark" || len(archive.Dashboards) != 1 || len(archive.Dashboards[0].Items) != 1 {
+		t.Fatalf("Unexpected archive: %+v", archive)
+	}
+
+	_, err = session.ReadArchive(bytes.NewBufferString(`{"version": 99}`))
+	if err == nil {
+		t.Errorf("Expected an error for a newer archive")
+	}
+
+	// the source account is not empty
+	_, err = session.NewTraggoSession(config.NewConfigToken(source.URL, TOKEN)).Restore(archive, false)
+	if !errors.Is(err, session.ErrNotEmpty) {
+		t.Errorf("Expected ErrNotEmpty, got: %v", err)
+	}
+
+	rs := &restoreServer{t: t, nextId: 100}
+	target := httptest.NewServer(rs)
+	defer target.Close()
+	report, err := session.NewTraggoSession(config.NewConfigToken(target.URL, TOKEN)).Restore(archive, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 'type' already exists
+	tags := rs.find("CreateTag")
+	if report.Tags != 1 || len(tags) != 1 || tags[0]["key"] != "project" || tags[0]["color"] != "#ff0000" {
+		t.Errorf("Expected project tag to be created, got: %v", tags)
+	}
+	if len(rs.find("SetUserSettings")) != 1 {
+		t.Errorf("Expected settings to be restored")
+	}
+	// oldest first
+	spans := rs.find("CreateTimeSpan")
+	if len(spans) != 2 || spans[0]["note"] != "first" || spans[1]["note"] != "second" {
+		t.Errorf("Unexpected time spans: %v", spans)
+	}
+	if len(report.Ids) != 3 || report.Ids[10] >= report.Ids[20] || report.Ids[30] == 0 {
+		t.Errorf("Unexpected id mapping: %v", report.Ids)
+	}
+	if timers := rs.find("StartTimer"); len(timers) != 1 || timers[0]["note"] != "running" {
+		t.Errorf("Unexpected timers: %v", timers)
+	}
+
+	// entries refer to the new range id
+	ranges := rs.find("AddDashboardRange")
+	entries := rs.find("AddDashboardEntry")
+	if report.Dashboards != 1 || len(ranges) != 1 || len(entries) != 1 {
+		t.Fatalf("Unexpected dashboard operations: %v", rs.ops)
+	}
+	rangeId := 0
+	for i, op := range rs.ops {
+		if op == "AddDashboardRange" {
+			rangeId = 101 + i
+		}
+	}
+	stats := entries[0]["stats"].(map[string]any)
+	if stats["rangeId"] != float64(rangeId) || entries[0]["title"] != "Projects" {
+		t.Errorf("Expected range id %d, got: %v", rangeId, entries[0])
+	}
+}
+
+func TestRestoreResume(t *testing.T) {
+	spans := session.TimeSpanTaskList{span(10, currentTime, time.Hour), span(20, currentTime.Add(2*time.Hour), time.Hour)}
+	spans[1].Note = "second"
+	archive := session.Archive{
+		Version:   session.ArchiveVersion,
+		Tags:      []session.TagDefinition{{Key: "Project", Color: "#ff0000"}, {Key: "project", Color: "#00ff00"}},
+		TimeSpans: spans,
+	}
+
+	fail := true
+	rs := &restoreServer{t: t, nextId: 100}
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if fail && bytes.Contains(body, []byte(`"second"`)) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		rs.ServeHTTP(w, r)
+	}))
+	defer target.Close()
+	s := session.NewTraggoSession(config.NewConfigToken(target.URL, TOKEN))
+
+	report, err := s.Restore(archive, false)
+	if err == nil {
+		t.Fatal("Expected the second time span to fail")
+	}
+	// tag names are case sensitive
+	if tags := rs.find("CreateTag"); len(tags) != 2 || report.Tags != 2 {
+		t.Errorf("Expected Project and project to be created, got: %v", tags)
+	}
+	if len(report.Ids) != 1 || report.Ids[10] == 0 {
+		t.Fatalf("Expected the first time span to be restored, got: %v", report.Ids)
+	}
+
+	fail = false
+	report, err = s.ResumeRestore(archive, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created := rs.find("CreateTimeSpan"); len(created) != 2 || created[1]["note"] != "second" {
+		t.Errorf("Expected the second time span alone to be created again, got: %v", created)
+	}
+	if len(report.Ids) != 2 || report.Ids[20] == 0 || report.Tags != 2 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+// historyServer serves the time spans of history, newest first, by pages
+// and counts the received operations
+type historyServer struct {
+	history map[int]session.TimeSpanTask
+	calls   map[string]int
+}
+
+func newHistoryServer(count int) *historyServer {
+	h := &historyServer{history: map[int]session.TimeSpanTask{}, calls: map[string]int{}}
+	for id := 1; id <= count; id++ {
+		h.add(id)
+	}
+	return h
+}
+
+func (h *historyServer) add(id int) {
+	start := currentTime.Add(time.Duration(id-10000) * time.Hour)
+	h.history[id] = session.TimeSpanTask{
+		TimerTask: session.TimerTask{
+			Id:    id,
+			Start: start,
+			Tags:  []session.Tag{{Key: "id", Value: strconv.Itoa(id % 10)}},
+		},
+		End: start.Add(time.Minute),
+	}
+}
+
+func (h *historyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var op struct {
+		OperationName string `json:"operationName"`
+		Variables     struct {
+			Id     int                   `json:"id"`
+			Start  time.Time             `json:"start"`
+			End    time.Time             `json:"end"`
+			Tags   []session.Tag         `json:"tags"`
+			Note   string                `json:"note"`
+			Cursor session.CursorRequest `json:"cursor"`
+		} `json:"variables"`
+	}
+	json.NewDecoder(r.Body).Decode(&op)
+	h.calls[op.OperationName]++
+
+	switch op.OperationName {
+	case "Trackers":
+		w.Write([]byte(`{"data":{"timers":[]}}`))
+	case "Tags":
+		keys := map[string]bool{}
+		for _, span := range h.history {
+			for _, tag := range span.Tags {
+				keys[tag.Key] = true
+			}
+		}
+		tags := []session.TagDefinition{}
+		for key := range keys {
+			tags = append(tags, session.TagDefinition{Key: key})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"tags": tags}})
+	case "CreateTimeSpan", "StartTimer", "UpdateTimeSpan":
+		id := op.Variables.Id
+		if id == 0 {
+			for known := range h.history {
+				id = max(id, known+1)
+			}
+		}
+		span := session.TimeSpanTask{
+			TimerTask: session.TimerTask{Id: id, Start: op.Variables.Start, Tags: op.Variables.Tags, Note: op.Variables.Note},
+			End:       op.Variables.End,
+		}
+		h.history[id] = span
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"createTimeSpan": span, "updateTimeSpan": span}})
+	case "RemoveTimeSpan":
+		span := h.history[op.Variables.Id]
+		delete(h.history, op.Variables.Id)
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"removeTimeSpan": span}})
+	case "TimeSpans":
+		cursor := op.Variables.Cursor
+		var ids []int
+		for id, span := range h.history {
+			if cursor.StartId != 0 && id > cursor.StartId {
+				continue
+			}
+			if !op.Variables.Start.IsZero() && (span.Start.Before(op.Variables.Start) || span.Start.After(op.Variables.End)) {
+				continue
+			}
+			ids = append(ids, id)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+		startId := 0
+		if len(ids) > 0 {
+			startId = ids[0]
+		}
+		if cursor.StartId != 0 {
+			startId = cursor.StartId
+		}
+		end := min(cursor.Offset+cursor.PageSize, len(ids))
+		page := session.TimeSpans{
+			Cursor: session.Cursor{
+				HasMore:  end < len(ids),
+				StartId:  startId,
+				Offset:   end,
+				PageSize: cursor.PageSize,
+			},
+			TimeSpans: session.TimeSpanTaskList{},
+		}
+		for _, id := range ids[cursor.Offset:end] {
+			page.TimeSpans = append(page.TimeSpans, h.history[id])
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": session.TimeSpansData{TimeSpans: page}})
+	}
+}
+
+func (h *historyServer) reset() {
+	h.calls = map[string]int{}
+}
+
+func TestCache(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	h := newHistoryServer(250)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	c := config.NewConfigToken(server.URL, TOKEN)
+	c.Cache.Path = filepath.Join(t.TempDir(), "cache.json")
+	s := session.NewTraggoSession(c)
+
+	// first search downloads the whole history
+	task, err := s.SearchTask(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task == nil || task.GetId() != 3 {
+		t.Fatalf("Expected task 3, got: %v", task)
+	}
+	if h.calls["TimeSpans"] != 3 {
+		t.Errorf("Expected 3 pages, got: %d", h.calls["TimeSpans"])
+	}
+
+	// moving to other tasks does not reach the server
+	h.reset()
+	for id := 1; id <= 250; id++ {
+		task, err = s.SearchTask(id)
+		if err != nil || task == nil {
+			t.Fatalf("Task %d not found: %v", id, err)
+		}
+	}
+	if len(h.calls) != 0 {
+		t.Errorf("Expected no request, got: %v", h.calls)
+	}
+	_, spans, err := s.AllTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 250 || spans[0].Id != 250 || spans[249].Id != 1 {
+		t.Errorf("Expected 250 tasks, newest first, got %d", len(spans))
+	}
+
+	// a new task is fetched incrementally
+	h.reset()
+	h.add(251)
+	task, err = s.SearchTask(251)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task == nil || task.GetId() != 251 {
+		t.Fatalf("Expected task 251, got: %v", task)
+	}
+	if h.calls["TimeSpans"] != 2 {
+		t.Errorf("Expected 2 pages for an incremental refresh, got: %d", h.calls["TimeSpans"])
+	}
+
+	// deleted tasks are removed from cache
+	err = s.Delete([]int{42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err = s.SearchTask(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task != nil {
+		t.Errorf("Expected deleted task to be forgotten, got: %v", task)
+	}
+
+	// cache is kept on disk
+	h.reset()
+	s = session.NewTraggoSession(c)
+	task, err = s.SearchTask(100)
+	if err != nil || task == nil {
+		t.Fatalf("Task 100 not found: %v", err)
+	}
+	if len(h.calls) != 0 {
+		t.Errorf("Expected no request, got: %v", h.calls)
+	}
+}
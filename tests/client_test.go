@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestGraphQLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"errors":[{"message":"timespan with id 1 does not exist","path":["removeTimeSpan", 0]}],"data":null}`))
+	}))
+	defer server.Close()
+
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+	err := s.Delete([]int{1})
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+	var gqlErrors session.Errors
+	if !errors.As(err, &gqlErrors) {
+		t.Fatalf("Expected session.Errors, got: %T", err)
+	}
+	if len(gqlErrors) != 1 || gqlErrors[0].Message != "timespan with id 1 does not exist" {
+		t.Errorf("Unexpected errors content: %v", gqlErrors)
+	}
+	if !strings.Contains(err.Error(), "removeTimeSpan.0") {
+		t.Errorf("Expected path in error message, got: %s", err)
+	}
+}
+
+func TestEmptyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":null}`))
+	}))
+	defer server.Close()
+
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+	err := s.Delete([]int{1})
+	if !errors.Is(err, session.ErrEmptyResponse) {
+		t.Fatalf("Expected ErrEmptyResponse, got: %v", err)
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`you need to login`))
+	}))
+	defer server.Close()
+
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+	err := s.Ping()
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Expected 401 error, got: %v", err)
+	}
+}
+
+func TestRetryQueries(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"timers":[]}}`))
+	}))
+	defer server.Close()
+
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+	s.Backoff = time.Millisecond
+	_, err := s.ListCurrentTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got: %d", calls)
+	}
+}
+
+func TestNoRetryMutations(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+	s.Backoff = time.Millisecond
+	err := s.Delete([]int{1})
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got: %d", calls)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(300 * time.Millisecond):
+		}
+	}))
+	defer server.Close()
+
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+	s.Timeout = 20 * time.Millisecond
+	s.Retries = 0
+	start := time.Now()
+	err := s.Ping()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got: %v", err)
+	}
+	if time.Since(start) > 200*time.Millisecond {
+		t.Errorf("Request has not been interrupted by timeout")
+	}
+}
+
+func TestRequestCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("No request expected with a cancelled context")
+	}))
+	defer server.Close()
+
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.ListCurrentTasksContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context canceled, got: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestContinueLatest(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	h := newHistoryServer(150)
+	// the newest id is not the latest time span
+	span := h.history[150]
+	span.Start = span.Start.Add(-1000 * time.Hour)
+	span.End = span.Start.Add(time.Minute)
+	h.history[150] = span
+	for _, id := range []int{140, 150} {
+		span := h.history[id]
+		span.Tags = append(span.Tags, session.Tag{Key: "project", Value: "foo"})
+		h.history[id] = span
+	}
+	server := httptest.NewServer(h)
+	defer server.Close()
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+
+	task, err := s.LatestTimeSpan(session.TaskFilter{Tags: []session.Tag{{Key: "id", Value: "0"}, {Key: "project"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task == nil || task.GetId() != 140 {
+		t.Fatalf("Expected task 140, got: %v", task)
+	}
+	if h.calls["TimeSpans"] != 1 {
+		t.Errorf("Expected a single page, got: %d", h.calls["TimeSpans"])
+	}
+
+	started, err := s.ContinueWith(task, []string{"project:bar", "ticket:42"}, "review")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []session.Tag{{Key: "id", Value: "0"}, {Key: "project", Value: "bar"}, {Key: "ticket", Value: "42"}}
+	if started.Id != 151 || started.Note != "review" || !started.Start.Equal(currentTime) || len(started.Tags) != len(want) {
+		t.Fatalf("Unexpected continued task: %v", started)
+	}
+	for i := range want {
+		if started.Tags[i] != want[i] {
+			t.Errorf("Expected tags %v, got: %v", want, started.Tags)
+			break
+		}
+	}
+
+	task, err = s.LatestTimeSpan(session.TaskFilter{Tags: []session.Tag{{Key: "project", Value: "none"}}})
+	if err != nil || task != nil {
+		t.Errorf("Expected no task, got: %v, %v", task, err)
+	}
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/utils"
+)
+
+func TestParseDate(t *testing.T) {
+	// a wednesday
+	now := time.Date(2025, time.February, 5, 15, 4, 5, 0, time.Local)
+	day := func(d int, clock ...int) time.Time {
+		c := append(clock, 0, 0, 0)
+		return time.Date(2025, time.February, d, c[0], c[1], c[2], 0, time.Local)
+	}
+	for _, test := range []struct {
+		input string
+		want  time.Time
+	}{
+		{"2025-02-01", day(1)},
+		{"2025-02-01 14:00", day(1, 14)},
+		{"2025-02-01 14:00:05", day(1, 14, 0, 5)},
+		{"2025-02-01T14:00:05", day(1, 14, 0, 5)},
+		{"2025-02-01T14:00:05Z", time.Date(2025, time.February, 1, 14, 0, 5, 0, time.UTC)},
+		{"now", now},
+		{"09:30", day(5, 9, 30)},
+		{"today", day(5)},
+		{"Yesterday 14:00", day(4, 14)},
+		{"tomorrow", day(6)},
+		{"wednesday", day(5)},
+		{"last wednesday", time.Date(2025, time.January, 29, 0, 0, 0, 0, time.Local)},
+		{"mon 9:00", day(3, 9)},
+		{"2h ago", now.Add(-2 * time.Hour)},
+		{"1h30m ago", now.Add(-90 * time.Minute)},
+		{"3d ago", now.Add(-72 * time.Hour)},
+		{"-15m", now.Add(-15 * time.Minute)},
+		{"+1w", now.Add(7 * 24 * time.Hour)},
+		{"in 2h", now.Add(2 * time.Hour)},
+		{"2025-W06", day(3)},
+		{"w06-3", day(5)},
+		{"2025-W01", time.Date(2024, time.December, 30, 0, 0, 0, 0, time.Local)},
+		{"2026-W53", time.Date(2026, time.December, 28, 0, 0, 0, 0, time.Local)},
+	} {
+		got, err := utils.ParseDate(test.input, now)
+		if err != nil {
+			t.Errorf("%s: %s", test.input, err)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("%s: got %s, expected %s", test.input, got, test.want)
+		}
+	}
+
+	for _, input := range []string{"", "someday", "25:00", "2025-13-01", "in 2h ago", "W54", "2025-W53", "last 9:00"} {
+		_, err := utils.ParseDate(input, now)
+		if err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
+
+func TestParseDatePeriod(t *testing.T) {
+	now := time.Date(2025, time.February, 5, 15, 4, 5, 0, time.Local)
+	for _, test := range []struct {
+		input      string
+		start, end time.Time
+	}{
+		{"yesterday", time.Date(2025, time.February, 4, 0, 0, 0, 0, time.Local), time.Date(2025, time.February, 5, 0, 0, 0, 0, time.Local)},
+		{"2025-W06", time.Date(2025, time.February, 3, 0, 0, 0, 0, time.Local), time.Date(2025, time.February, 10, 0, 0, 0, 0, time.Local)},
+		{"2h ago", now.Add(-2 * time.Hour), now.Add(-2 * time.Hour)},
+	} {
+		start, end, err := utils.ParseDatePeriod(test.input, now)
+		if err != nil {
+			t.Errorf("%s: %s", test.input, err)
+			continue
+		}
+		if !start.Equal(test.start) || !end.Equal(test.end) {
+			t.Errorf("%s: got %s - %s, expected %s - %s", test.input, start, end, test.start, test.end)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for input, want := range map[string]time.Duration{
+		"15m":    15 * time.Minute,
+		"1h30m":  90 * time.Minute,
+		"2d":     48 * time.Hour,
+		"1w 1d":  8 * 24 * time.Hour,
+		"10 min": 10 * time.Minute,
+	} {
+		got, err := utils.ParseDuration(input)
+		if err != nil || got != want {
+			t.Errorf("%s: got %s (%v), expected %s", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "2 days", "h"} {
+		_, err := utils.ParseDuration(input)
+		if err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
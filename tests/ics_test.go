@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestToICS(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	long := strings.Repeat("é", 60)
+	spans := session.TimeSpanTaskList{
+		span(42, currentTime.Add(-3*time.Hour), time.Hour,
+			session.Tag{Key: "project", Value: "cli"},
+			session.Tag{Key: "type", Value: "dev"}),
+	}
+	spans[0].Note = "fix; parser, then\nrelease " + long
+	timers := session.TimersData{Timers: []session.TimerTask{
+		{Id: 43, Start: currentTime.Add(-time.Hour)},
+	}}
+
+	ics := session.ToICS(timers, spans, session.ICSOptions{Host: "traggo.example.com", SummaryTags: []string{"project", "type"}})
+
+	if !strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(ics, "END:VCALENDAR\r\n") {
+		t.Errorf("Invalid calendar envelope: %q", ics)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Line longer than 75 octets: %q", line)
+		}
+	}
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	expected := []string{
+		"UID:traggo-42@traggo.example.com",
+		"SUMMARY:cli / dev",
+		"DTSTART:" + currentTime.Add(-3*time.Hour).UTC().Format("20060102T150405Z"),
+		`DESCRIPTION:fix\; parser\, then\nrelease ` + long,
+		"STATUS:CONFIRMED",
+		"UID:traggo-43@traggo.example.com",
+		"SUMMARY:Traggo",
+		"DTEND:" + currentTime.UTC().Format("20060102T150405Z"),
+		"STATUS:TENTATIVE",
+	}
+	for _, e := range expected {
+		if !strings.Contains(unfolded, e+"\r\n") {
+			t.Errorf("Missing line %q", e)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestParseImport(t *testing.T) {
+	csvFile := `id,tags,start,end,duration,note
+1,project:cli;type:dev,2025-12-01T09:00:00+01:00,2025-12-01T10:00:00+01:00,3600,first
+2,project:cli,2025-12-01 11:00:00,2025-12-01 10:00:00,0,backwards
+3,nokey,2025-12-01 11:00:00,2025-12-01 12:00:00,0,bad tag
+`
+	entries, err := session.ParseImport(strings.NewReader(csvFile), session.ImportCSV, session.DefaultImportMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got: %d", len(entries))
+	}
+	if entries[0].Err != nil || len(entries[0].Span.Tags) != 2 || entries[0].Span.Note != "first" || entries[0].Span.End.Sub(entries[0].Span.Start) != time.Hour {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Err == nil || entries[1].Line != 3 {
+		t.Errorf("Expected an error on line 3, got: %+v", entries[1])
+	}
+	if entries[2].Err == nil {
+		t.Errorf("Expected an error for a tag without value")
+	}
+
+	toggl := `User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount ()
+Jane,jane@example.com,ACME,Website,,Landing page,Yes,2025-12-01,09:00:00,2025-12-01,10:30:00,01:30:00,"design, review",
+`
+	m := session.DefaultImportMapping()
+	m.Columns["User"] = "user"
+	entries, err = session.ParseImport(strings.NewReader(toggl), session.ImportToggl, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Err != nil {
+		t.Fatalf("Unexpected Toggl entries: %+v", entries)
+	}
+	span := entries[0].Span
+	for _, tag := range []session.Tag{{Key: "tag", Value: "design"}, {Key: "tag", Value: "review"}, {Key: "client", Value: "ACME"}, {Key: "project", Value: "Website"}, {Key: "user", Value: "Jane"}} {
+		if !span.HasTag(tag.Key, tag.Value) {
+			t.Errorf("Missing tag %v in %v", tag, span.Tags)
+		}
+	}
+	if span.Note != "Landing page" || span.End.Sub(span.Start) != 90*time.Minute {
+		t.Errorf("Unexpected Toggl span: %+v", span)
+	}
+
+	clockify := "\ufeff\"Project\",\"Client\",\"Description\",\"Task\",\"User\",\"Tags\",\"Start Date\",\"Start Time\",\"End Date\",\"End Time\"\n" +
+		"\"Website\",\"ACME\",\"Call\",\"\",\"Jane\",\"\",\"12/01/2025\",\"02:00:00 PM\",\"12/01/2025\",\"02:45:00 PM\"\n"
+	entries, err = session.ParseImport(strings.NewReader(clockify), session.ImportClockify, session.DefaultImportMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Err != nil || entries[0].Span.Start.Hour() != 14 || !entries[0].Span.HasTag("project", "Website") {
+		t.Errorf("Unexpected Clockify entries: %+v", entries)
+	}
+
+	jsonFile := `[{"id":1,"tags":["project:cli"],"start":"2025-12-01T09:00:00+01:00","end":"2025-12-01T09:30:00+01:00","duration":1800,"note":"from json"}]`
+	entries, err = session.ParseImport(strings.NewReader(jsonFile), session.ImportJSON, session.DefaultImportMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Err != nil || !entries[0].Span.HasTag("project", "cli") || entries[0].Span.Note != "from json" {
+		t.Errorf("Unexpected JSON entries: %+v", entries)
+	}
+}
+
+func TestImport(t *testing.T) {
+	existing := span(7, currentTime.Add(9*time.Hour), time.Hour)
+	var created []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var op struct {
+			OperationName string `json:"operationName"`
+			Variables     struct {
+				Note string `json:"note"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&op)
+		switch op.OperationName {
+		case "TimeSpans":
+			json.NewEncoder(w).Encode(map[string]any{"data": session.TimeSpansData{
+				TimeSpans: session.TimeSpans{TimeSpans: session.TimeSpanTaskList{existing}},
+			}})
+		case "CreateTimeSpan":
+			created = append(created, op.Variables.Note)
+			if op.Variables.Note == "rejected" {
+				w.Write([]byte(`{"errors":[{"message":"tag 'foo' does not exist"}],"data":null}`))
+				return
+			}
+			w.Write([]byte(`{"data":{"createTimeSpan":{"id":8}}}`))
+		}
+	}))
+	defer server.Close()
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+
+	entry := func(line int, start time.Duration, note string) session.ImportEntry {
+		sp := span(0, currentTime.Add(start), time.Hour)
+		sp.Note = note
+		return session.ImportEntry{Line: line, Span: sp}
+	}
+	entries := []session.ImportEntry{
+		entry(2, 9*time.Hour, ""), // duplicate of existing
+		entry(3, 11*time.Hour, "new"),
+		entry(4, 11*time.Hour, "new"), // duplicate in file
+		entry(5, 11*time.Hour, "parallel"),
+		entry(6, 13*time.Hour, "rejected"),
+		{Line: 7, Err: session.ErrEmptyResponse},
+	}
+
+	report, err := s.Import(entries, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 0 {
+		t.Errorf("Nothing should be created with a dry run, got: %v", created)
+	}
+	if len(report.Created) != 3 || len(report.Duplicates) != 2 || len(report.Failures) != 1 {
+		t.Errorf("Unexpected dry run report: %+v", report)
+	}
+
+	report, err = s.Import(entries, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 3 || created[0] != "new" || created[1] != "parallel" {
+		t.Errorf("Unexpected created time spans: %v", created)
+	}
+	if len(report.Created) != 2 || len(report.Duplicates) != 2 || len(report.Failures) != 2 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if report.Failures[1].Line != 6 {
+		t.Errorf("Expected failure on line 6, got: %d", report.Failures[1].Line)
+	}
+}
+
+func TestImportUndoFile(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	h := newHistoryServer(0)
+	server := httptest.NewServer(h)
+	defer server.Close()
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+
+	// as written by rm
+	tags := []session.Tag{{Key: "project", Value: "cli"}}
+	timers := session.TimersData{Timers: []session.TimerTask{{Id: 1, Start: currentTime.Add(-time.Hour), Tags: tags, Note: "running"}}}
+	spans := session.TimeSpanTaskList{span(2, currentTime.Add(-3*time.Hour), time.Hour)}
+	undo, err := json.Marshal(append(timers.Records(), spans.Records()...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := session.ParseImport(strings.NewReader(string(undo)), session.ImportJSON, session.DefaultImportMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Err != nil || entries[1].Err != nil {
+		t.Fatalf("Unexpected entries: %+v", entries)
+	}
+	if !entries[0].Span.End.IsZero() || !entries[0].Span.Start.Equal(currentTime.Add(-time.Hour)) {
+		t.Errorf("Expected a timer, got: %+v", entries[0].Span)
+	}
+
+	// timers are not started by default
+	report, err := s.Import(entries, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Created) != 1 || len(report.Running) != 1 || h.calls["StartTimer"] != 0 {
+		t.Fatalf("Unexpected report without starting timers: %+v", report)
+	}
+
+	h = newHistoryServer(0)
+	server.Config.Handler = h
+	report, err = s.Import(entries, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Created) != 2 || len(report.Failures) != 0 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if h.calls["StartTimer"] != 1 || h.calls["CreateTimeSpan"] != 1 {
+		t.Errorf("Expected a timer and a time span to be created, got: %v", h.calls)
+	}
+	timer := h.history[report.Created[0].Id]
+	if !timer.End.IsZero() || timer.Note != "running" || !timer.HasTag("project", "cli") {
+		t.Errorf("Unexpected restarted timer: %+v", timer)
+	}
+}
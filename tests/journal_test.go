@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestJournal(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	h := newHistoryServer(10)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	c := config.NewConfigToken(server.URL, TOKEN)
+	c.Undo.Dir = t.TempDir()
+	c.Cache.Path = filepath.Join(t.TempDir(), "cache.json")
+	s := session.NewTraggoSession(c)
+
+	_, err := s.Undo(1)
+	if !errors.Is(err, session.ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got: %v", err)
+	}
+
+	// one operation for several tasks, known by the cache
+	_, _, err = s.AllTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Delete([]int{3, 4, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.history) != 7 {
+		t.Fatalf("Expected 7 tasks, got %d", len(h.history))
+	}
+	original := newHistoryServer(10).history
+	entries, err := s.Undo(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Op != session.OpDelete || len(h.history) != 10 {
+		t.Fatalf("Expected the 3 tasks to be recreated, got %d tasks: %v", len(h.history), entries)
+	}
+	// recreated with new ids
+	for _, id := range []int{3, 4, 5} {
+		want := original[id]
+		recreated := false
+		for newId, span := range h.history {
+			if newId > 10 && span.Start.Equal(want.Start) && span.End.Equal(want.End) && span.HasTag("id", want.Tags[0].Value) {
+				recreated = true
+			}
+		}
+		if !recreated {
+			t.Errorf("Task %d is not recreated", id)
+		}
+	}
+
+	// ids of recreated tasks are followed
+	_, err = s.Redo(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.history) != 7 || h.history[11].Id != 0 {
+		t.Errorf("Expected recreated tasks to be deleted again, got %d tasks", len(h.history))
+	}
+	_, err = s.Redo(1)
+	if err == nil {
+		t.Errorf("Expected nothing to redo")
+	}
+
+	// previous fields are restored, from a new session reading the journal file
+	span := h.history[7]
+	span.Note = "changed"
+	span.End = span.End.Add(time.Hour)
+	err = s.UpdateTimeSpanTask(span)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.history[7].Note != "changed" {
+		t.Fatalf("Expected task 7 to be updated")
+	}
+	s = session.NewTraggoSession(c)
+	entries, err = s.Undo(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].Op != session.OpUpdate || h.history[7].Note != "" || !h.history[7].End.Equal(original[7].End) {
+		t.Errorf("Expected task 7 to be restored, got: %+v", h.history[7])
+	}
+
+	// a new operation forgets undone ones
+	_, err = s.Start([]string{"id:new"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Redo(1)
+	if err == nil {
+		t.Errorf("Expected nothing to redo after a new operation")
+	}
+	if got := len(s.Journal.Entries()); got != 2 {
+		t.Errorf("Expected 2 operations in journal, got: %d", got)
+	}
+
+	// the previous state is given, nothing is searched
+	c.Cache.Path = ""
+	s = session.NewTraggoSession(c)
+	h.reset()
+	span = h.history[8]
+	changed := span
+	changed.Note = "given"
+	err = s.UpdateTaskFrom(span, changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.calls["TimeSpans"] != 0 || h.calls["Trackers"] != 0 {
+		t.Errorf("Expected no lookup before the update, got: %v", h.calls)
+	}
+	entries = s.Journal.Entries()
+	if last := entries[len(entries)-1]; last.Op != session.OpUpdate || last.Changes[0].Before.Note != "" {
+		t.Errorf("Expected the update to be recorded, got: %+v", last)
+	}
+}
+
+func TestJournalWithoutCache(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	h := newHistoryServer(10)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	c := config.NewConfigToken(server.URL, TOKEN)
+	c.Undo.Dir = t.TempDir()
+	c.Cache.Disabled = true
+	s := session.NewTraggoSession(c)
+	original := newHistoryServer(10).history
+
+	// deleted tasks are recorded as returned by Traggo
+	err := s.Delete([]int{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := s.Undo(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Op != session.OpDelete || len(h.history) != 10 {
+		t.Fatalf("Expected task 3 to be recreated, got %d tasks: %v", len(h.history), entries)
+	}
+
+	// updated tasks are searched before the update
+	span := h.history[7]
+	span.Note = "changed"
+	err = s.UpdateTimeSpanTask(span)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err = s.Undo(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].Op != session.OpUpdate || h.history[7].Note != "" || !h.history[7].End.Equal(original[7].End) {
+		t.Errorf("Expected task 7 to be restored, got: %+v", h.history[7])
+	}
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestLint(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	at := func(h, m int) time.Time {
+		return time.Date(2025, 11, 28, h, m, 0, 0, time.Local)
+	}
+	span := func(id int, start, end time.Time) session.TimeSpanTask {
+		return session.TimeSpanTask{TimerTask: session.TimerTask{Id: id, Start: start}, End: end}
+	}
+	spans := session.TimeSpanTaskList{
+		span(1, at(9, 0), at(10, 30)),
+		span(2, at(10, 0), at(12, 0)),   // overlaps 1
+		span(3, at(11, 0), at(11, 30)),  // inside 2
+		span(4, at(14, 0), at(17, 0)),   // gap after 2
+		span(5, at(17, 0), at(17, 0)),   // no duration
+		span(6, at(18, 30), at(17, 30)), // negative duration
+		span(7, at(19, 0), at(20, 0)),   // after working hours
+	}
+	timers := session.TimersData{Timers: []session.TimerTask{
+		{Id: 8, Start: at(20, 30)},
+		{Id: 9, Start: currentTime.Add(-time.Hour)},
+	}}
+	opts := session.LintOptions{WorkStart: 9 * time.Hour, WorkEnd: 18 * time.Hour, Gap: 15 * time.Minute, MaxTimer: 10 * time.Hour}
+	issues := session.Lint(timers, spans, opts)
+
+	type expected struct {
+		kind string
+		ids  []int
+		fix  bool
+	}
+	want := []expected{
+		{session.IssueOverlap, []int{1, 2}, true},
+		{session.IssueOverlap, []int{2, 3}, false},
+		{session.IssueGap, []int{2, 4}, false},
+		{session.IssueDuration, []int{5}, false},
+		{session.IssueGap, []int{4, 7}, false}, // until the end of working hours
+		{session.IssueDuration, []int{6}, true},
+		{session.IssueLongTimer, []int{8}, true},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("Expected %d issues, got: %v", len(want), issues.Rows())
+	}
+	for i, w := range want {
+		issue := issues[i]
+		ok := issue.Kind == w.kind && len(issue.Tasks) == len(w.ids) && (issue.Fix != nil) == w.fix
+		for k := range w.ids {
+			ok = ok && issue.Tasks[k].GetId() == w.ids[k]
+		}
+		if !ok {
+			t.Errorf("Issue %d: expected %v, got: %v", i, w, issues.Rows()[i])
+		}
+	}
+	if gap := issues[2]; !gap.Start.Equal(at(12, 0)) || !gap.End.Equal(at(14, 0)) {
+		t.Errorf("Unexpected gap: %v", issues.Rows()[2])
+	}
+	// a timer started after working hours stops after the longest duration
+	if fix := issues[6].Fix; !fix.Stop || !fix.Task.End.Equal(at(20, 30).Add(10*time.Hour)) {
+		t.Errorf("Unexpected fix: %v", fix)
+	}
+
+	// fixes are updates of time spans
+	h := newHistoryServer(0)
+	h.history[1] = spans[0]
+	server := httptest.NewServer(h)
+	defer server.Close()
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+	err := s.ApplyLintFix(*issues[0].Fix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.history[1].End.Equal(at(10, 0)) {
+		t.Errorf("Expected task 1 to end at 10:00, got: %v", h.history[1])
+	}
+
+	// a span overlapping several others is ended once, at the earliest start
+	chained := session.TimeSpanTaskList{
+		span(11, at(9, 0), at(12, 0)),
+		span(12, at(10, 0), at(13, 0)),
+		span(13, at(11, 0), at(14, 0)),
+	}
+	for _, sp := range chained {
+		h.history[sp.Id] = sp
+	}
+	issues = session.Lint(session.TimersData{}, chained, session.LintOptions{})
+	fixes := 0
+	for _, issue := range issues {
+		if issue.Fix == nil {
+			continue
+		}
+		fixes++
+		err := s.ApplyLintFix(*issue.Fix)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(issues) != 3 || fixes != 2 {
+		t.Errorf("Expected 3 overlaps and 2 fixes, got: %v", issues.Rows())
+	}
+	if !h.history[11].End.Equal(at(10, 0)) || !h.history[12].End.Equal(at(11, 0)) {
+		t.Errorf("Expected task 11 to end at 10:00 and 12 at 11:00, got: %v %v", h.history[11], h.history[12])
+	}
+	fixed := session.TimeSpanTaskList{h.history[11], h.history[12], h.history[13]}
+	if issues := session.Lint(session.TimersData{}, fixed, session.LintOptions{}); len(issues) != 0 {
+		t.Errorf("Expected no issue once fixed, got: %v", issues.Rows())
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestOverlap(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	h := newHistoryServer(0)
+	h.history[1] = session.TimeSpanTask{
+		TimerTask: session.TimerTask{Id: 1, Start: currentTime.Add(-3 * time.Hour), Tags: []session.Tag{{Key: "id", Value: "1"}}},
+		End:       currentTime.Add(-2 * time.Hour),
+	}
+	server := httptest.NewServer(h)
+	defer server.Close()
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+
+	span := func(start, end time.Duration) session.TimeSpanTask {
+		return session.TimeSpanTask{TimerTask: session.TimerTask{Start: currentTime.Add(start)}, End: currentTime.Add(end)}
+	}
+	err := s.CheckOverlap(span(-150*time.Minute, -time.Hour))
+	if !errors.Is(err, session.ErrOverlap) {
+		t.Errorf("Expected an overlap, got: %v", err)
+	}
+	// adjacent spans do not overlap
+	err = s.CheckOverlap(span(-2*time.Hour, -time.Hour))
+	if err != nil {
+		t.Errorf("Unexpected overlap: %v", err)
+	}
+	// an existing span does not overlap itself
+	updated := h.history[1]
+	updated.End = currentTime.Add(-90 * time.Minute)
+	err = s.CheckOverlap(updated)
+	if err != nil {
+		t.Errorf("Unexpected overlap: %v", err)
+	}
+	// a timer started in the past runs until now
+	err = s.CheckOverlap(session.TimeSpanTask{TimerTask: session.TimerTask{Start: currentTime.Add(-150 * time.Minute)}})
+	if !errors.Is(err, session.ErrOverlap) {
+		t.Errorf("Expected an overlap, got: %v", err)
+	}
+}
+
+func TestStartStopAt(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	h := newHistoryServer(0)
+	server := httptest.NewServer(h)
+	defer server.Close()
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+
+	_, err := s.StartAt([]string{"id:1"}, "", currentTime.Add(time.Minute))
+	if err == nil {
+		t.Error("Expected an error for a timer starting in the future")
+	}
+	_, err = s.StopAt([]int{1}, currentTime.Add(time.Minute))
+	if err == nil {
+		t.Error("Expected an error for a timer stopping in the future")
+	}
+	if len(h.calls) != 0 {
+		t.Errorf("Nothing should be sent, got: %v", h.calls)
+	}
+
+	task, err := s.StartAt([]string{"id:1"}, "late", currentTime.Add(-15*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.Start.Equal(currentTime.Add(-15*time.Minute)) || !h.history[task.Id].Start.Equal(task.Start) {
+		t.Errorf("Unexpected started task: %v", task)
+	}
+}
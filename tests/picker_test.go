@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	session "github.com/kalidor/traggo_cli/session"
+	"github.com/kalidor/traggo_cli/tui"
+)
+
+func pickerTasks() []session.GenericTask {
+	span := func(id int, project, note string) session.TimeSpanTask {
+		start := currentTime.Add(-time.Duration(id) * time.Hour)
+		return session.TimeSpanTask{
+			TimerTask: session.TimerTask{Id: id, Start: start, Tags: []session.Tag{{Key: "project", Value: project}}, Note: note},
+			End:       start.Add(30 * time.Minute),
+		}
+	}
+	return []session.GenericTask{
+		session.TimerTask{Id: 4, Start: currentTime.Add(-time.Minute), Tags: []session.Tag{{Key: "project", Value: "cli"}}},
+		span(3, "traggo", "weekly meeting"),
+		span(2, "cli", "fix picker"),
+		span(1, "traggo", "review"),
+	}
+}
+
+func typeKeys(m tea.Model, keys ...tea.KeyMsg) tea.Model {
+	for _, k := range keys {
+		m, _ = m.Update(k)
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func pickedIds(m tea.Model) []int {
+	var ids []int
+	for _, task := range m.(tui.Picker).Selected() {
+		ids = append(ids, task.GetId())
+	}
+	return ids
+}
+
+func TestPicker(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	// letters in order, words in any order
+	m := typeKeys(tui.NewPicker(pickerTasks(), false), runes("trg mtg"), enter)
+	if ids := pickedIds(m); len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("Expected task 3, got: %v", ids)
+	}
+	// the most recent first among equal matches
+	m = typeKeys(tui.NewPicker(pickerTasks(), false), runes("cli"), tea.KeyMsg{Type: tea.KeyDown}, enter)
+	if ids := pickedIds(m); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("Expected task 2, got: %v", ids)
+	}
+	// several tasks
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+	m = typeKeys(tui.NewPicker(pickerTasks(), true), runes("traggo"), tab, tab, enter)
+	if ids := pickedIds(m); len(ids) != 2 || ids[0] != 3 || ids[1] != 1 {
+		t.Errorf("Expected tasks 3 and 1, got: %v", ids)
+	}
+	m = typeKeys(tui.NewPicker(pickerTasks(), true), runes("traggo"), tea.KeyMsg{Type: tea.KeyEsc})
+	if ids := pickedIds(m); len(ids) != 0 {
+		t.Errorf("Expected nothing once cancelled, got: %v", ids)
+	}
+	m = typeKeys(tui.NewPicker(pickerTasks(), false), runes("nothing"), enter)
+	if ids := pickedIds(m); len(ids) != 0 {
+		t.Errorf("Expected no match, got: %v", ids)
+	}
+}
@@ -0,0 +1,215 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+// unreachableURL returns the URL of a server which is already closed
+func unreachableURL() string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+	return server.URL
+}
+
+func TestQueueWhenUnreachable(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	c := config.NewConfigToken(unreachableURL(), TOKEN)
+	c.Offline.Queue = filepath.Join(t.TempDir(), "queue.json")
+	s := session.NewTraggoSession(c)
+	s.Backoff = time.Millisecond
+
+	task, err := s.Start([]string{"tag1:value1"}, "offline")
+	if !errors.Is(err, session.ErrQueued) {
+		t.Fatalf("Expected ErrQueued, got: %v", err)
+	}
+	if !task.Start.Equal(currentTime) || task.Note != "offline" {
+		t.Errorf("Unexpected queued task: %v", task)
+	}
+	_, err = s.Stop([]int{12, 13})
+	if !errors.Is(err, session.ErrQueued) {
+		t.Fatalf("Expected ErrQueued, got: %v", err)
+	}
+
+	ops, err := s.Queue.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 3 || ops[0].Query != "StartTimer" || ops[1].Query != "StopTimer" || ops[2].Query != "StopTimer" {
+		t.Fatalf("Unexpected queue content: %v", ops)
+	}
+
+	// Nothing is queued for queries
+	_, err = s.ListCurrentTasks()
+	if !errors.Is(err, session.ErrUnreachable) {
+		t.Fatalf("Expected ErrUnreachable, got: %v", err)
+	}
+	ops, _ = s.Queue.Load()
+	if len(ops) != 3 {
+		t.Errorf("Expected 3 queued operations, got: %d", len(ops))
+	}
+}
+
+func TestNoQueueWhenMaybeDelivered(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	status := http.StatusGatewayTimeout
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status == 0 {
+			// longer than the timeout of the client
+			select {
+			case <-r.Context().Done():
+			case <-time.After(300 * time.Millisecond):
+			}
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	c := config.NewConfigToken(server.URL, TOKEN)
+	c.Offline.Queue = filepath.Join(t.TempDir(), "queue.json")
+	s := session.NewTraggoSession(c)
+	s.Timeout = 20 * time.Millisecond
+
+	// the timer may have been created, it must not be created again by sync
+	for _, status = range []int{http.StatusGatewayTimeout, 0} {
+		_, err := s.Start([]string{"tag1:value1"}, "")
+		if err == nil || errors.Is(err, session.ErrQueued) || errors.Is(err, session.ErrUnreachable) {
+			t.Errorf("Status %d: expected a plain error, got: %v", status, err)
+		}
+	}
+	status = http.StatusServiceUnavailable
+	_, err := s.Start([]string{"tag1:value1"}, "")
+	if !errors.Is(err, session.ErrQueued) {
+		t.Errorf("Expected ErrQueued, got: %v", err)
+	}
+	ops, _ := s.Queue.Load()
+	if len(ops) != 1 {
+		t.Errorf("Expected a single queued operation, got: %v", ops)
+	}
+}
+
+func TestSync(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	queuePath := filepath.Join(t.TempDir(), "queue.json")
+	c := config.NewConfigToken(unreachableURL(), TOKEN)
+	c.Offline.Queue = queuePath
+	s := session.NewTraggoSession(c)
+	s.Start([]string{"tag1:value1"}, "offline")
+	s.Stop([]int{12})
+	s.Delete([]int{13})
+
+	// Replay once back online, with a timestamp later than the original one
+	session.TimeNow = func() time.Time {
+		return currentTime.Add(time.Hour)
+	}
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var op struct {
+			OperationName string `json:"operationName"`
+			Variables     struct {
+				Id    int       `json:"id"`
+				Start time.Time `json:"start"`
+				End   time.Time `json:"end"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&op)
+		received = append(received, op.OperationName)
+		switch op.OperationName {
+		case "StartTimer":
+			if !op.Variables.Start.Equal(currentTime) {
+				t.Errorf("Expected original start time, got: %s", op.Variables.Start)
+			}
+			w.Write([]byte(`{"data":{"createTimeSpan":{"id":20}}}`))
+		case "StopTimer":
+			if !op.Variables.End.Equal(currentTime) {
+				t.Errorf("Expected original end time, got: %s", op.Variables.End)
+			}
+			w.Write([]byte(`{"data":{"stopTimeSpan":{"id":12}}}`))
+		case "RemoveTimeSpan":
+			w.Write([]byte(`{"errors":[{"message":"timespan with id 13 does not exist"}],"data":null}`))
+		}
+	}))
+	defer server.Close()
+
+	c = config.NewConfigToken(server.URL, TOKEN)
+	c.Offline.Queue = queuePath
+	s = session.NewTraggoSession(c)
+	report, err := s.Sync(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 3 || received[0] != "StartTimer" || received[1] != "StopTimer" || received[2] != "RemoveTimeSpan" {
+		t.Errorf("Operations not replayed in order: %v", received)
+	}
+	if len(report.Sent) != 2 || len(report.Conflicts) != 1 || len(report.Pending) != 0 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+
+	// Conflicts are kept until dropped
+	ops, _ := s.Queue.Load()
+	if len(ops) != 1 || ops[0].Query != "RemoveTimeSpan" {
+		t.Fatalf("Expected conflict in queue, got: %v", ops)
+	}
+	_, err = s.Sync(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, _ = s.Queue.Load()
+	if len(ops) != 0 {
+		t.Errorf("Expected empty queue, got: %v", ops)
+	}
+}
+
+func TestSyncMaybeApplied(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	queuePath := filepath.Join(t.TempDir(), "queue.json")
+	c := config.NewConfigToken(unreachableURL(), TOKEN)
+	c.Offline.Queue = queuePath
+	s := session.NewTraggoSession(c)
+	s.Start([]string{"tag1:value1"}, "offline")
+	s.Delete([]int{13})
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			// the gateway gave up, Traggo may have created the timer
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		w.Write([]byte(`{"errors":[{"message":"timespan with id 13 does not exist"}],"data":null}`))
+	}))
+	defer server.Close()
+
+	c = config.NewConfigToken(server.URL, TOKEN)
+	c.Offline.Queue = queuePath
+	s = session.NewTraggoSession(c)
+	report, err := s.Sync(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Uncertain) != 1 || report.Uncertain[0].Operation.Query != "StartTimer" || len(report.Conflicts) != 1 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	// only the rejected operation is sent again
+	ops, _ := s.Queue.Load()
+	if len(ops) != 1 || ops[0].Query != "RemoveTimeSpan" {
+		t.Errorf("Expected the conflict alone in queue, got: %v", ops)
+	}
+}
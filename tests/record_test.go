@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestRecords(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	start := currentTime.Add(-90 * time.Minute)
+	timers := session.TimersData{Timers: []session.TimerTask{
+		{Id: 1, Start: start, Note: "running"},
+	}}
+	spans := session.TimeSpanTaskList{
+		{
+			TimerTask: session.TimerTask{Id: 2, Start: start, Tags: []session.Tag{{Key: "type", Value: "dev"}, {Key: "proj", Value: "cli"}}},
+			End:       start.Add(time.Hour),
+		},
+	}
+
+	records := append(timers.Records(), spans.Records()...)
+	raw, err := json.Marshal(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]any
+	json.Unmarshal(raw, &decoded)
+	if len(decoded) != 2 {
+		t.Fatalf("Expected 2 records, got: %s", raw)
+	}
+	for _, r := range decoded {
+		for _, field := range session.RecordHeader {
+			if _, ok := r[field]; !ok {
+				t.Errorf("Missing field '%s' in %v", field, r)
+			}
+		}
+	}
+	if decoded[0]["end"] != nil || decoded[0]["duration"] != float64(5400) {
+		t.Errorf("Unexpected running record: %v", decoded[0])
+	}
+	if decoded[1]["duration"] != float64(3600) {
+		t.Errorf("Unexpected duration: %v", decoded[1]["duration"])
+	}
+
+	expected := []string{"2", "type:dev;proj:cli", start.Format(time.RFC3339), start.Add(time.Hour).Format(time.RFC3339), "3600", ""}
+	if !reflect.DeepEqual(records[1].Fields(), expected) {
+		t.Errorf("Expected %v, got %v", expected, records[1].Fields())
+	}
+
+	// empty lists are encoded as an empty array
+	raw, _ = json.Marshal(session.TimeSpanTaskList{}.Records())
+	if string(raw) != "[]" {
+		t.Errorf("Expected [], got: %s", raw)
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func span(id int, start time.Time, d time.Duration, tags ...session.Tag) session.TimeSpanTask {
+	return session.TimeSpanTask{
+		TimerTask: session.TimerTask{Id: id, Start: start, Tags: tags},
+		End:       start.Add(d),
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	// monday 2025-12-01 00:00
+	session.TimeNow = func() time.Time {
+		return currentTime.Add(50 * time.Hour)
+	}
+	cli := session.Tag{Key: "project", Value: "cli"}
+	web := session.Tag{Key: "project", Value: "web"}
+	spans := session.TimeSpanTaskList{
+		span(1, currentTime.Add(9*time.Hour), 2*time.Hour, cli),
+		span(2, currentTime.Add(14*time.Hour), time.Hour, web),
+		// crosses midnight: 1h on monday, 2h on tuesday
+		span(3, currentTime.Add(23*time.Hour), 3*time.Hour, cli),
+		// starts before the range: only 30 minutes count
+		span(4, currentTime.Add(-time.Hour), 90*time.Minute),
+	}
+	// running since tuesday 23:00, counted up to now (wednesday 02:00)
+	timers := session.TimersData{Timers: []session.TimerTask{
+		{Id: 5, Start: currentTime.Add(47 * time.Hour), Tags: []session.Tag{web}},
+	}}
+
+	report, err := session.BuildReport(timers, spans, []string{"project"}, session.IntervalNone, currentTime, currentTime.Add(72*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Periods) != 1 {
+		t.Fatalf("Expected 1 period, got: %d", len(report.Periods))
+	}
+	lines := report.Periods[0].Lines
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got: %v", lines)
+	}
+	if lines[0].Values[0] != "cli" || lines[0].Duration != 5*3600 {
+		t.Errorf("Unexpected first line: %v", lines[0])
+	}
+	if lines[1].Values[0] != "web" || lines[1].Duration != 4*3600 {
+		t.Errorf("Unexpected second line: %v", lines[1])
+	}
+	if lines[2].Values[0] != "" || lines[2].Duration != 30*60 {
+		t.Errorf("Unexpected untagged line: %v", lines[2])
+	}
+	if report.Duration != 9*3600+30*60 {
+		t.Errorf("Unexpected total: %d", report.Duration)
+	}
+
+	report, err = session.BuildReport(timers, spans, []string{"project"}, session.IntervalDay, currentTime, currentTime.Add(72*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Periods) != 3 {
+		t.Fatalf("Expected 3 days, got: %d", len(report.Periods))
+	}
+	expected := []int64{4*3600 + 30*60, 3 * 3600, 2 * 3600}
+	for i, p := range report.Periods {
+		if p.Duration != expected[i] {
+			t.Errorf("Day %d: expected %d, got %d", i, expected[i], p.Duration)
+		}
+	}
+
+	report, err = session.BuildReport(timers, spans, nil, session.IntervalWeek, currentTime, currentTime.Add(72*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Periods) != 1 || !report.Periods[0].Start.Equal(currentTime) {
+		t.Errorf("Expected one week starting on monday, got: %v", report.Periods)
+	}
+
+	_, err = session.BuildReport(timers, spans, nil, "year", currentTime, currentTime)
+	if err == nil {
+		t.Error("Expected an error for an invalid interval")
+	}
+}
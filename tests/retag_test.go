@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestRetagRule(t *testing.T) {
+	tags := []session.Tag{{Key: "ticket", Value: "OLD-12"}, {Key: "project", Value: "cli"}, {Key: "ticket", Value: "NEW-12"}}
+	for _, test := range []struct {
+		from, to string
+		regexp   bool
+		want     []session.Tag
+		changed  bool
+	}{
+		// duplicate removed
+		{"ticket:OLD-*", "ticket:NEW-*", false, []session.Tag{{Key: "ticket", Value: "NEW-12"}, {Key: "project", Value: "cli"}}, true},
+		{"ticket:OLD-?2", "ticket:X?", false, []session.Tag{{Key: "ticket", Value: "X1"}, {Key: "project", Value: "cli"}, {Key: "ticket", Value: "NEW-12"}}, true},
+		{"project:", "client:", false, []session.Tag{{Key: "ticket", Value: "OLD-12"}, {Key: "client", Value: "cli"}, {Key: "ticket", Value: "NEW-12"}}, true},
+		{`ticket:(OLD|NEW)-(\d+)`, "ticket:T-$2", true, []session.Tag{{Key: "ticket", Value: "T-12"}, {Key: "project", Value: "cli"}}, true},
+		{"ticket:OLD", "ticket:NEW", false, tags, false},
+	} {
+		rule, err := session.NewRetagRule(test.from, test.to, test.regexp)
+		if err != nil {
+			t.Fatalf("%s: %s", test.from, err)
+		}
+		got, changed := rule.Apply(tags)
+		if changed != test.changed || len(got) != len(test.want) {
+			t.Errorf("%s -> %s: got %v", test.from, test.to, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%s -> %s: got %v, expected %v", test.from, test.to, got, test.want)
+				break
+			}
+		}
+	}
+
+	_, err := session.NewRetagRule("ticket:*", "ticket:**", false)
+	if err == nil {
+		t.Errorf("Expected an error for extra wildcards")
+	}
+	_, err = session.NewRetagRule("ticket", "other", false)
+	if err == nil {
+		t.Errorf("Expected an error without ':'")
+	}
+}
+
+func TestApplyRetag(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	h := newHistoryServer(30)
+	server := httptest.NewServer(h)
+	defer server.Close()
+	c := config.NewConfigToken(server.URL, TOKEN)
+	c.Undo.Dir = t.TempDir()
+	s := session.NewTraggoSession(c)
+
+	rule, err := session.NewRetagRule("id:7", "id:seven", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := s.PlanRetag(rule, session.TaskFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("Expected tasks 7, 17 and 27, got: %v", changes.Rows())
+	}
+	var progress []int
+	report, err := s.ApplyRetag(changes, 2, func(done, total int) {
+		progress = append(progress, done)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Updated != 3 || len(report.Failures) != 0 || len(progress) != 2 || progress[1] != 3 {
+		t.Errorf("Unexpected report %+v, progress %v", report, progress)
+	}
+	for _, id := range []int{7, 17, 27} {
+		if !h.history[id].HasTag("id", "seven") {
+			t.Errorf("Task %d not retagged: %v", id, h.history[id].Tags)
+		}
+	}
+
+	// new tag names must be known
+	rule, err = session.NewRetagRule("id:", "client:", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.PlanRetag(rule, session.TaskFilter{})
+	if err == nil {
+		t.Errorf("Expected an error for the unknown tag client")
+	}
+
+	// a single undo reverts the whole retag
+	_, err = s.Undo(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{7, 17, 27} {
+		if !h.history[id].HasTag("id", "7") {
+			t.Errorf("Task %d not restored: %v", id, h.history[id].Tags)
+		}
+	}
+}
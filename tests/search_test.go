@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestSearchTasks(t *testing.T) {
+	h := newHistoryServer(250)
+	server := httptest.NewServer(h)
+	defer server.Close()
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+
+	// all matches, newest first
+	tasks, err := s.SearchTasks(session.TaskFilter{TagKey: "id", TagValue: "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 25 || tasks[0].GetId() != 243 || tasks[24].GetId() != 3 {
+		t.Fatalf("Unexpected matches: %d", len(tasks))
+	}
+
+	// pagination stops once the id is found
+	h.reset()
+	task, err := s.SearchTask(240)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task == nil || task.GetId() != 240 {
+		t.Fatalf("Expected task 240, got: %v", task)
+	}
+	if h.calls["TimeSpans"] != 1 {
+		t.Errorf("Expected 1 page, got: %d", h.calls["TimeSpans"])
+	}
+
+	// unknown ids are not searched through the history
+	h.reset()
+	task, err = s.SearchTask(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task != nil {
+		t.Errorf("Expected no task, got: %v", task)
+	}
+	if h.calls["TimeSpans"] != 1 {
+		t.Errorf("Expected 1 page, got: %d", h.calls["TimeSpans"])
+	}
+
+	// date range is sent to the server
+	h.reset()
+	from := h.history[200].Start
+	tasks, err = s.SearchTasks(session.TaskFilter{From: from, To: from.Add(9 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 10 || tasks[0].GetId() != 209 || tasks[9].GetId() != 200 {
+		t.Errorf("Unexpected tasks between dates: %d", len(tasks))
+	}
+	if h.calls["TimeSpans"] != 1 {
+		t.Errorf("Expected 1 page, got: %d", h.calls["TimeSpans"])
+	}
+
+	// first match only
+	task, err = s.SearchTaskByTag("id", "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task == nil || task.GetId() != 247 {
+		t.Errorf("Expected task 247, got: %v", task)
+	}
+
+	// pagination stops once all the ids are found
+	h.reset()
+	tasks, err = s.SearchTasks(session.TaskFilter{Ids: []int{230, 150, 145}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 || tasks[0].GetId() != 230 || tasks[2].GetId() != 145 {
+		t.Errorf("Unexpected tasks for ids: %v", tasks)
+	}
+	if h.calls["TimeSpans"] != 2 {
+		t.Errorf("Expected 2 pages, got: %d", h.calls["TimeSpans"])
+	}
+}
@@ -150,9 +150,16 @@ func TestStart(t *testing.T) {
 			t.Errorf("POST data are not expected one.")
 
 		}
+		w.Write([]byte(`{"data":{"createTimeSpan":{"id":1,"start":"2025-12-01T00:00:00Z","tags":[{"key":"tag1","value":"value1"},{"key":"tag2","value":"value2"}],"note":"this is a note"}}}`))
 	}))
 	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
-	s.Start([]string{"tag1:value1", "tag2:value2"}, "this is a note")
+	task, err := s.Start([]string{"tag1:value1", "tag2:value2"}, "this is a note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Id != 1 || task.Note != "this is a note" || len(task.Tags) != 2 {
+		t.Errorf("Unexpected started task: %v", task)
+	}
 
 	defer server.Close()
 }
@@ -203,9 +210,16 @@ func TestStop(t *testing.T) {
 			t.Errorf("POST data are not expected one.")
 
 		}
+		w.Write([]byte(`{"data":{"stopTimeSpan":{"id":1,"start":"2025-11-30T22:00:00Z","end":"2025-12-01T00:00:00Z","tags":[],"note":""}}}`))
 	}))
 	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
-	s.Stop(config.ColorsDef{}, []int{1})
+	stopped, err := s.Stop([]int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stopped) != 1 || stopped[0].Id != 1 || stopped[0].End.IsZero() {
+		t.Errorf("Unexpected stopped task: %v", stopped)
+	}
 
 	defer server.Close()
 }
@@ -234,7 +248,7 @@ func TestDelete(t *testing.T) {
 		expected := expectedOperation{
 			OperationName: "RemoveTimeSpan",
 			Variables:     variables,
-			Query:         "mutation RemoveTimeSpan($id: Int!) {\n  removeTimeSpan(id: $id) {\n    id\n    __typename\n  }\n}\n",
+			Query:         "mutation RemoveTimeSpan($id: Int!) {\n  removeTimeSpan(id: $id) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    note\n    __typename\n  }\n}\n",
 		}
 
 		var received expectedOperation
@@ -251,9 +265,13 @@ func TestDelete(t *testing.T) {
 			t.Errorf("POST data are not expected one.")
 
 		}
+		w.Write([]byte(`{"data":{"removeTimeSpan":{"id":1}}}`))
 	}))
 	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
-	s.Delete([]int{1})
+	err := s.Delete([]int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	defer server.Close()
 }
@@ -318,9 +336,13 @@ func TestUpdateTimerStask(t *testing.T) {
 			t.Errorf("POST data are not expected one.")
 
 		}
+		w.Write([]byte(`{"data":{"updateTimeSpan":{"id":123,"start":"2025-12-01T00:00:00Z","tags":[{"key":"tag1","value":"value1"}],"note":"this is a note"}}}`))
 	}))
 	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
-	s.UpdateTimerTask(task)
+	err := s.UpdateTimerTask(task)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	defer server.Close()
 }
@@ -387,9 +409,13 @@ func TestUpdateTimeSpanTask(t *testing.T) {
 			t.Errorf("POST data are not expected one.")
 
 		}
+		w.Write([]byte(`{"data":{"updateTimeSpan":{"id":123,"start":"2025-12-01T00:00:00Z","end":"2025-12-01T02:00:00Z","tags":[{"key":"tag1","value":"value1"}],"note":"this is a note"}}}`))
 	}))
 	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
-	s.UpdateTimeSpanTask(task)
+	err := s.UpdateTimeSpanTask(task)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	defer server.Close()
 }
@@ -407,7 +433,7 @@ func TestContinue(t *testing.T) {
 			Id:    123,
 			Start: session.TimeNow(),
 		},
-		Query: "mutation Continue($id: Int!, $start: Time!) {\n  copyTimeSpan(id: $id, start: $start) {\n    id\n    start\n    __typename\n  }\n}",
+		Query: "mutation Continue($id: Int!, $start: Time!) {\n  copyTimeSpan(id: $id, start: $start) {\n    id\n    start\n    end\n    tags {\n      key\n      value\n      __typename\n    }\n    oldStart\n    note\n    __typename\n  }\n}",
 	}
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("Content-Type") != "application/json" {
@@ -429,11 +455,34 @@ func TestContinue(t *testing.T) {
 			t.Errorf("POST data are not expected one.")
 		}
 
+		w.Write([]byte(`{"data":{"copyTimeSpan":{"id":124,"start":"2025-12-01T00:00:00Z"}}}`))
 	}))
 	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
-	s.Continue(session.TimerTask{
+	err := s.Continue(session.TimerTask{
 		Id: 123,
 	})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	defer server.Close()
 }
+
+func TestTimersSelection(t *testing.T) {
+	timers := session.TimersData{Timers: []session.TimerTask{
+		{Id: 1, Start: currentTime.Add(-time.Hour), Tags: []session.Tag{{Key: "project", Value: "foo"}}},
+		{Id: 2, Start: currentTime.Add(-time.Minute), Tags: []session.Tag{{Key: "project", Value: "bar"}}},
+		{Id: 3, Start: currentTime.Add(-2 * time.Hour), Tags: []session.Tag{{Key: "project", Value: "foo"}}},
+	}}
+	last, ok := timers.Last()
+	if !ok || last.Id != 2 {
+		t.Errorf("Expected timer 2 as last, got: %v", last)
+	}
+	found := timers.Filter(session.TaskFilter{TagKey: "project", TagValue: "foo"})
+	if len(found.Timers) != 2 || found.Timers[0].Id != 1 || found.Timers[1].Id != 3 {
+		t.Errorf("Unexpected timers: %v", found)
+	}
+	if _, ok := (session.TimersData{}).Last(); ok {
+		t.Error("No timer expected")
+	}
+}
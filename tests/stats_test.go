@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var op struct {
+			OperationName string             `json:"operationName"`
+			Variables     session.StatsQuery `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&op)
+		if op.OperationName != "Stats" {
+			t.Errorf("Expected Stats operation, got: %s", op.OperationName)
+		}
+		if len(op.Variables.Ranges) != 2 || len(op.Variables.Tags) != 1 || op.Variables.Tags[0] != "project" {
+			t.Errorf("Unexpected variables: %+v", op.Variables)
+		}
+		if len(op.Variables.ExcludeTags) != 1 || op.Variables.ExcludeTags[0].Value != "meeting" {
+			t.Errorf("Unexpected excluded tags: %+v", op.Variables.ExcludeTags)
+		}
+		w.Write([]byte(`{"data":{"stats":[
+			{"start":"2025-12-01T00:00:00+01:00","end":"2025-12-02T00:00:00+01:00","entries":[
+				{"key":"project","value":"web","timeSpendInSeconds":600},
+				{"key":"project","value":"cli","timeSpendInSeconds":7200}]},
+			{"start":"2025-12-02T00:00:00+01:00","end":"2025-12-02T12:00:00+01:00","entries":[]}]}}`))
+	}))
+	defer server.Close()
+
+	ranges, err := session.StatsRanges(currentTime, currentTime.Add(36*time.Hour), session.IntervalDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 2 || !ranges[1].Start.Equal(currentTime.Add(24*time.Hour)) || !ranges[1].End.Equal(currentTime.Add(36*time.Hour)) {
+		t.Fatalf("Unexpected ranges: %v", ranges)
+	}
+
+	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
+	stats, err := s.Stats(session.StatsQuery{
+		Ranges:      ranges,
+		Tags:        []string{"project"},
+		ExcludeTags: []session.Tag{{Key: "type", Value: "meeting"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 || len(stats[0].Entries) != 2 {
+		t.Fatalf("Unexpected stats: %v", stats)
+	}
+	// sorted by time spent
+	if stats[0].Entries[0].Value != "cli" {
+		t.Errorf("Expected cli first, got: %v", stats[0].Entries)
+	}
+	rows := stats.Rows()
+	if len(rows) != 2 || rows[0][4] != "7200" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
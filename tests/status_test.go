@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestStatusCache(t *testing.T) {
+	now := currentTime
+	session.TimeNow = func() time.Time {
+		return now
+	}
+	h := newHistoryServer(0)
+	server := httptest.NewServer(h)
+	defer server.Close()
+	c := config.NewConfigToken(server.URL, TOKEN)
+	c.Status.Path = filepath.Join(t.TempDir(), "status.json")
+	s := session.NewTraggoSession(c)
+
+	for i := 0; i < 3; i++ {
+		_, _, err := s.CurrentTimers()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h.calls["Trackers"] != 1 {
+		t.Errorf("Expected a single request within the TTL, got: %v", h.calls)
+	}
+
+	// another session, as another prompt, reads the same file
+	other := session.NewTraggoSession(c)
+	_, _, err := other.CurrentTimers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.calls["Trackers"] != 1 {
+		t.Errorf("Expected the cache file to be used, got: %v", h.calls)
+	}
+
+	now = now.Add(config.DefaultStatusTTL)
+	s.CurrentTimers()
+	if h.calls["Trackers"] != 2 {
+		t.Errorf("Expected a request once the TTL expired, got: %v", h.calls)
+	}
+
+	// a mutation outdates the cache
+	_, err = s.Start([]string{"id:1"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.CurrentTimers()
+	if h.calls["Trackers"] != 3 {
+		t.Errorf("Expected a request after a mutation, got: %v", h.calls)
+	}
+}
+
+func TestStatusStale(t *testing.T) {
+	now := currentTime
+	session.TimeNow = func() time.Time {
+		return now
+	}
+	hang := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hang {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(300 * time.Millisecond):
+			}
+			return
+		}
+		w.Write([]byte(`{"data":{"timers":[{"id":1,"start":"2025-11-30T23:00:00Z"}]}}`))
+	}))
+	defer server.Close()
+	c := config.NewConfigToken(server.URL, TOKEN)
+	c.Status.Path = filepath.Join(t.TempDir(), "status.json")
+	c.Status.Timeout = config.Duration{Duration: 20 * time.Millisecond}
+	s := session.NewTraggoSession(c)
+
+	timers, stale, err := s.CurrentTimers()
+	if err != nil || stale || len(timers.Timers) != 1 {
+		t.Fatalf("Unexpected timers: %v %t %v", timers, stale, err)
+	}
+
+	// the last known timers are used rather than waiting for Traggo
+	hang = true
+	now = now.Add(time.Hour)
+	start := time.Now()
+	timers, stale, err = s.CurrentTimers()
+	if err != nil || !stale || len(timers.Timers) != 1 {
+		t.Errorf("Expected the stale timers, got: %v %t %v", timers, stale, err)
+	}
+	if time.Since(start) > 200*time.Millisecond {
+		t.Error("Status has not been interrupted by its timeout")
+	}
+
+	// a mutation while Traggo fails keeps the last known timers
+	now = currentTime
+	s.Status.Invalidate()
+	timers, stale, err = s.CurrentTimers()
+	if err != nil || !stale || len(timers.Timers) != 1 {
+		t.Errorf("Expected the stale timers after a mutation, got: %v %t %v", timers, stale, err)
+	}
+
+	// nothing known
+	os.Remove(c.Status.Path)
+	_, _, err = s.CurrentTimers()
+	if err == nil {
+		t.Error("Expected an error without known timers")
+	}
+}
+
+func TestStatus(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	status := session.NewStatus(session.TimersData{})
+	if status.Running {
+		t.Errorf("Expected no running timer, got: %v", status)
+	}
+
+	timers := session.TimersData{Timers: []session.TimerTask{
+		{Id: 1, Start: currentTime.Add(-3 * time.Hour), Tags: []session.Tag{{Key: "project", Value: "old"}}},
+		{Id: 2, Start: currentTime.Add(-65 * time.Minute), Tags: []session.Tag{{Key: "project", Value: "foo"}, {Key: "type", Value: "dev"}}, Note: "bug"},
+	}}
+	status = session.NewStatus(timers)
+	if !status.Running || status.Id != 2 || status.Count != 2 {
+		t.Fatalf("Expected the latest timer of 2, got: %v", status)
+	}
+	tests := map[string]string{
+		config.DefaultStatusTemplate:             "project:foo type:dev 1h05m",
+		"{{.Values}} {{.Note}} {{.Count}}":       "foo dev bug 2",
+		"{{.Start.Format \"15:04\"}} {{.Id}}":    currentTime.Add(-65*time.Minute).Format("15:04") + " 2",
+		"{{if gt .Count 1}}+{{end}}{{.Elapsed}}": "+1h05m",
+	}
+	for tmpl, expected := range tests {
+		got, err := status.Render(tmpl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != expected {
+			t.Errorf("%s: expected '%s', got '%s'", tmpl, expected, got)
+		}
+	}
+	_, err := status.Render("{{.Unknown}}")
+	if err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+	if session.FormatElapsed(59*time.Second) != "0m" || session.FormatElapsed(10*time.Hour) != "10h00m" {
+		t.Error("Unexpected elapsed format")
+	}
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+// switchServer runs timer 1, and refuses to start timers with the tag fail:yes
+func switchServer(t *testing.T, received *[]string, times *[]time.Time) *httptest.Server {
+	timer := session.TimeSpanTask{TimerTask: session.TimerTask{Id: 1, Start: currentTime.Add(-time.Hour), Tags: []session.Tag{{Key: "project", Value: "foo"}}}}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var op struct {
+			OperationName string `json:"operationName"`
+			Variables     struct {
+				Start time.Time     `json:"start"`
+				End   time.Time     `json:"end"`
+				Tags  []session.Tag `json:"tags"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&op)
+		*received = append(*received, op.OperationName)
+		switch op.OperationName {
+		case "StopTimer":
+			*times = append(*times, op.Variables.End)
+			span := timer
+			span.End = op.Variables.End
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"stopTimeSpan": span}})
+		case "StartTimer":
+			if len(op.Variables.Tags) > 0 && op.Variables.Tags[0].Key == "fail" {
+				w.Write([]byte(`{"errors":[{"message":"tag 'fail' does not exist"}],"data":null}`))
+				return
+			}
+			*times = append(*times, op.Variables.Start)
+			started := session.TimerTask{Id: 2, Start: op.Variables.Start, Tags: op.Variables.Tags}
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"createTimeSpan": started}})
+		case "UpdateTimeSpan":
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"updateTimeSpan": timer}})
+		default:
+			t.Errorf("Unexpected operation %s", op.OperationName)
+		}
+	}))
+}
+
+func TestSwitch(t *testing.T) {
+	session.TimeNow = func() time.Time {
+		return currentTime
+	}
+	var received []string
+	var times []time.Time
+	server := switchServer(t, &received, &times)
+	defer server.Close()
+	c := config.NewConfigToken(server.URL, TOKEN)
+	c.Undo.Dir = t.TempDir()
+	s := session.NewTraggoSession(c)
+
+	stopped, started, err := s.Switch([]int{1}, []string{"project:bar"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stopped) != 1 || stopped[0].Id != 1 || started.Id != 2 {
+		t.Fatalf("Unexpected switch: %v %v", stopped, started)
+	}
+	if len(times) != 2 || !times[0].Equal(times[1]) {
+		t.Errorf("Expected the same time to stop and start, got: %v", times)
+	}
+	entries := s.Journal.Entries()
+	if len(entries) != 1 || entries[0].Op != session.OpSwitch || len(entries[0].Changes) != 2 {
+		t.Errorf("Expected a single switch operation, got: %v", entries)
+	}
+
+	// the stopped timer runs again
+	received = nil
+	stopped, _, err = s.Switch([]int{1}, []string{"fail:yes"}, "")
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if len(stopped) != 0 || len(received) != 3 || received[2] != "UpdateTimeSpan" {
+		t.Errorf("Expected the stop to be rolled back, got: %v", received)
+	}
+	if len(s.Journal.Entries()) != 1 {
+		t.Errorf("Nothing should be recorded, got: %v", s.Journal.Entries())
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+func TestTags(t *testing.T) {
+	var updates []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var op struct {
+			OperationName string         `json:"operationName"`
+			Variables     map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&op)
+		switch op.OperationName {
+		case "Tags":
+			w.Write([]byte(`{"data":{"tags":[{"key":"type","color":"#00ff00","usages":3},{"key":"project","color":"#ff0000","usages":12}]}}`))
+		case "CreateTag":
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"createTag": op.Variables}})
+		case "UpdateTag":
+			updates = append(updates, op.Variables)
+			w.Write([]byte(`{"data":{"updateTag":{"key":"client","color":"#ff0000","usages":12}}}`))
+		case "RemoveTag":
+			w.Write([]byte(`{"data":{"removeTag":null}}`))
+		}
+	}))
+	defer server.Close()
+
+	c := config.NewConfigToken(server.URL, TOKEN)
+	c.Tags = config.TagsDef{{TagName: "project"}, {TagName: "client"}, {TagName: "Type"}}
+	s := session.NewTraggoSession(c)
+
+	tags, err := s.GetTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0].Key != "project" || tags[0].Usages != 12 {
+		t.Errorf("Expected tags sorted by name, got: %v", tags)
+	}
+	missing, err := s.MissingTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != "client" {
+		t.Errorf("Expected client to be missing, got: %v", missing)
+	}
+	if s.CheckTagsInConfig() == nil {
+		t.Errorf("Expected an error for unknown tags")
+	}
+
+	color := session.TagColor("client")
+	if session.CheckColor(color) != nil || color != session.TagColor("client") {
+		t.Errorf("Expected a stable valid color, got: %s", color)
+	}
+	if session.CheckColor("red") == nil {
+		t.Errorf("Expected an error for an invalid color")
+	}
+	tag, err := s.CreateTag("client", color)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.Key != "client" || tag.Color != color {
+		t.Errorf("Unexpected created tag: %v", tag)
+	}
+
+	// newKey is only sent to rename
+	_, err = s.UpdateTag("project", "client", "#ff0000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.UpdateTag("project", "", "#0000ff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 || updates[0]["newKey"] != "client" {
+		t.Fatalf("Unexpected updates: %v", updates)
+	}
+	if _, ok := updates[1]["newKey"]; ok || updates[1]["color"] != "#0000ff" {
+		t.Errorf("Unexpected recolor: %v", updates[1])
+	}
+
+	if s.RemoveTag("unknown") == nil {
+		t.Errorf("Expected an error for an unknown tag")
+	}
+}
@@ -33,7 +33,10 @@ func TestVersion(t *testing.T) {
 		}
 	}))
 	s := session.NewTraggoSession(config.NewConfigToken(server.URL, TOKEN))
-	version := s.GetVersion()
+	version, err := s.GetVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if version.Name != "1.2.3" || version.Commit != "deadbeef..." || version.BuildDate.Format(time.DateTime) != "2025-04-28 15:21:13" {
 		fmt.Println(version.BuildDate.Format(time.DateTime))
 		t.Error("Version data are not expected value")
@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kalidor/traggo_cli/config"
+	session "github.com/kalidor/traggo_cli/session"
+)
+
+// watchServer runs timers, and stops them on StopTimer
+func watchServer(t *testing.T, timers map[int]session.TimerTask, stops map[int]time.Time) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var op struct {
+			OperationName string `json:"operationName"`
+			Variables     struct {
+				Id  int       `json:"id"`
+				End time.Time `json:"end"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&op)
+		switch op.OperationName {
+		case "Trackers":
+			running := []session.TimerTask{}
+			for _, timer := range timers {
+				running = append(running, timer)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"timers": running}})
+		case "StopTimer":
+			stops[op.Variables.Id] = op.Variables.End
+			span := session.TimeSpanTask{TimerTask: timers[op.Variables.Id], End: op.Variables.End}
+			delete(timers, op.Variables.Id)
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"stopTimeSpan": span}})
+		default:
+			t.Errorf("Unexpected operation %s", op.OperationName)
+		}
+	}))
+}
+
+func TestWatch(t *testing.T) {
+	now := currentTime.Add(8 * time.Hour)
+	session.TimeNow = func() time.Time {
+		return now
+	}
+	tags := []session.Tag{{Key: "project", Value: "foo"}}
+	timers := map[int]session.TimerTask{
+		1: {Id: 1, Start: currentTime.Add(16 * time.Hour), Tags: tags},
+		2: {Id: 2, Start: currentTime.Add(7 * time.Hour), Tags: tags},
+	}
+	stops := map[int]time.Time{}
+	server := watchServer(t, timers, stops)
+	defer server.Close()
+	c := config.NewConfigToken(server.URL, TOKEN)
+	c.Undo.Dir = t.TempDir()
+
+	var notified []session.WatchAlert
+	w := session.Watcher{
+		Session:     session.NewTraggoSession(c),
+		MaxDuration: 4 * time.Hour,
+		EndOfDay:    19 * time.Hour,
+		Notifiers: []session.Notifier{session.NotifierFunc(func(ctx context.Context, alert session.WatchAlert) error {
+			notified = append(notified, alert)
+			return nil
+		})},
+	}
+	ctx := context.Background()
+
+	// 08:00, timer 1 starts in the future
+	alerts, err := w.Check(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("Nothing expected yet, got: %v", alerts)
+	}
+
+	// 11:30, timer 2 runs for more than 4h
+	now = currentTime.Add(11*time.Hour + 30*time.Minute)
+	alerts, err = w.Check(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Timer.Id != 2 || alerts[0].Reason != session.ReasonMaxDuration || !alerts[0].At.Equal(currentTime.Add(11*time.Hour)) {
+		t.Errorf("Expected timer 2 to be reported, got: %v", alerts)
+	}
+	if len(notified) != 1 {
+		t.Errorf("Expected a notification, got: %v", notified)
+	}
+
+	// each timer is reported once
+	now = currentTime.Add(12 * time.Hour)
+	alerts, _ = w.Check(ctx)
+	if len(alerts) != 0 {
+		t.Errorf("Timer 2 was already reported, got: %v", alerts)
+	}
+
+	// 19:30, timer 1 crossed the end of the day before running for 4h, it is stopped then
+	w.AutoStop = true
+	now = currentTime.Add(19*time.Hour + 30*time.Minute)
+	alerts, err = w.Check(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Timer.Id != 1 || alerts[0].Reason != session.ReasonEndOfDay {
+		t.Fatalf("Expected timer 1 to be reported, got: %v", alerts)
+	}
+	if len(stops) != 1 || !stops[1].Equal(currentTime.Add(19*time.Hour)) {
+		t.Errorf("Expected timer 1 to be stopped at 19:00, got: %v", stops)
+	}
+	if _, ok := timers[1]; ok {
+		t.Error("Timer 1 should not run anymore")
+	}
+
+	// a timer started after the end of the day is reported the next day
+	timers[3] = session.TimerTask{Id: 3, Start: currentTime.Add(20 * time.Hour), Tags: tags}
+	w.MaxDuration = 0
+	now = currentTime.Add(23 * time.Hour)
+	alerts, _ = w.Check(ctx)
+	if len(alerts) != 0 {
+		t.Errorf("Nothing expected before the next end of day, got: %v", alerts)
+	}
+	now = currentTime.Add(43 * time.Hour)
+	alerts, _ = w.Check(ctx)
+	if len(alerts) != 1 || alerts[0].Timer.Id != 3 || !alerts[0].At.Equal(currentTime.AddDate(0, 0, 1).Add(19*time.Hour)) {
+		t.Errorf("Expected timer 3 to be reported at the next end of day, got: %v", alerts)
+	}
+}
+
+func TestWatchState(t *testing.T) {
+	now := currentTime.Add(23 * time.Hour)
+	session.TimeNow = func() time.Time {
+		return now
+	}
+	timers := map[int]session.TimerTask{1: {Id: 1, Start: currentTime.Add(20 * time.Hour)}}
+	server := watchServer(t, timers, map[int]time.Time{})
+	defer server.Close()
+	c := config.NewConfigToken(server.URL, TOKEN)
+	state := filepath.Join(t.TempDir(), "watch.json")
+
+	// each run from cron is a new watcher, ending the day at midnight
+	check := func() []session.WatchAlert {
+		w := session.Watcher{Session: session.NewTraggoSession(c), EndOfDay: 24 * time.Hour, StatePath: state}
+		alerts, err := w.Check(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return alerts
+	}
+	if alerts := check(); len(alerts) != 0 {
+		t.Errorf("Nothing expected before midnight, got: %v", alerts)
+	}
+	now = currentTime.Add(25 * time.Hour)
+	alerts := check()
+	if len(alerts) != 1 || !alerts[0].At.Equal(currentTime.AddDate(0, 0, 1)) {
+		t.Errorf("Expected timer 1 to be reported at midnight, got: %v", alerts)
+	}
+	if alerts := check(); len(alerts) != 0 {
+		t.Errorf("Timer 1 was already reported by a previous run, got: %v", alerts)
+	}
+}
+
+func TestWatchStopFailure(t *testing.T) {
+	now := currentTime.Add(20 * time.Hour)
+	session.TimeNow = func() time.Time {
+		return now
+	}
+	fail := true
+	stops := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var op struct {
+			OperationName string `json:"operationName"`
+		}
+		json.NewDecoder(r.Body).Decode(&op)
+		switch op.OperationName {
+		case "Trackers":
+			w.Write([]byte(`{"data":{"timers":[{"id":1,"start":"2025-12-01T08:00:00+01:00"}]}}`))
+		case "StopTimer":
+			stops++
+			if fail {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte(`{"data":{"stopTimeSpan":{"id":1}}}`))
+		}
+	}))
+	defer server.Close()
+	c := config.NewConfigToken(server.URL, TOKEN)
+	state := filepath.Join(t.TempDir(), "watch.json")
+
+	check := func() ([]session.WatchAlert, error) {
+		w := session.Watcher{Session: session.NewTraggoSession(c), EndOfDay: 19 * time.Hour, AutoStop: true, StatePath: state}
+		return w.Check(context.Background())
+	}
+	alerts, err := check()
+	if len(alerts) != 1 || err == nil {
+		t.Fatalf("Expected the failing stop to be returned, got: %v %v", alerts, err)
+	}
+	// not recorded as reported, the next run stops it
+	fail = false
+	alerts, err = check()
+	if len(alerts) != 1 || err != nil || stops != 2 {
+		t.Errorf("Expected the timer to be stopped again, got: %v %v (%d stops)", alerts, err, stops)
+	}
+	alerts, err = check()
+	if len(alerts) != 0 || err != nil || stops != 2 {
+		t.Errorf("Expected the timer to be reported once stopped, got: %v %v (%d stops)", alerts, err, stops)
+	}
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"fmt"
 	"io"
 	"strconv"
 
@@ -36,11 +37,19 @@ type deleteModel struct {
 	yes         string
 	no          string
 	choices     []string
-	taskId      int
+	task        session.GenericTask
 }
 
-func initDelete(dump io.Writer, session *session.Traggo, mainState sessionState, taskIdStr string) deleteModel {
+func initDelete(dump io.Writer, session *session.Traggo, mainState sessionState, taskIdStr string) (deleteModel, error) {
 	i, _ := strconv.Atoi(taskIdStr)
+	// the task is kept for the journal, so the deletion can be undone
+	task, err := session.SearchTask(i)
+	if err != nil {
+		return deleteModel{}, err
+	}
+	if task == nil {
+		return deleteModel{}, fmt.Errorf("unable to retrieve task id %d", i)
+	}
 	m := deleteModel{
 		commonModel: commonModel{
 			dump:    dump,
@@ -50,9 +59,9 @@ func initDelete(dump io.Writer, session *session.Traggo, mainState sessionState,
 		yes:     "YES",
 		no:      "NO",
 		choices: []string{"NO", "YES"},
-		taskId:  i,
+		task:    task,
 	}
-	return m
+	return m, nil
 }
 
 func (m deleteModel) Init() tea.Cmd {
@@ -70,12 +79,13 @@ func (m deleteModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "enter":
+			var err error
 			if m.deleteState == yesView {
-				m.session.Delete([]int{m.taskId})
+				err = m.session.DeleteTasks([]session.GenericTask{m.task})
 			}
 			m.state = TableView
 
-			return NewMainModel(m.dump, m.session, m.state)
+			return backToMain(m.commonModel, err)
 
 		case "esc":
 			m.state = TableView
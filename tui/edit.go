@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"sort"
@@ -45,11 +46,11 @@ type editModel struct {
 
 // Validator functions to ensure valid input
 func datetimeValidator(s string) error {
-	_, err := utils.StrToTime(s, time.DateTime)
+	_, err := utils.ParseDate(s, time.Now())
 	return err
 }
 
-func initEdit(dump io.Writer, s *session.Traggo, mainState sessionState, taskIdStr string) editModel {
+func initEdit(dump io.Writer, s *session.Traggo, mainState sessionState, taskIdStr string) (editModel, error) {
 	numTags := len(s.Tags)
 	var inputs []textinput.Model = make([]textinput.Model, numTags+3) // +3 for start, end and Note
 	sort.Sort(config.ByPosition(s.Tags))
@@ -70,7 +71,14 @@ func initEdit(dump io.Writer, s *session.Traggo, mainState sessionState, taskIdS
 		}
 	} else {
 		taskId, _ := strconv.Atoi(taskIdStr)
-		task = s.SearchTask(taskId)
+		var err error
+		task, err = s.SearchTask(taskId)
+		if err != nil {
+			return editModel{}, err
+		}
+		if task == nil {
+			return editModel{}, fmt.Errorf("unable to retrieve task id %d", taskId)
+		}
 
 		taskStartString = task.GetStartString()
 		taskStopString = task.GetStopString()
@@ -139,7 +147,7 @@ func initEdit(dump io.Writer, s *session.Traggo, mainState sessionState, taskIdS
 		help:    help,
 		keys:    editKeys,
 		focused: -1,
-	}
+	}, nil
 }
 
 func (e editModel) Init() tea.Cmd {
@@ -224,7 +232,15 @@ func (e editModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				// it's a new task
 				if e.task == nil {
-					e.session.Start(tags, e.inputs[len(e.session.Tags)].Value())
+					_, err := e.session.Start(tags, e.inputs[len(e.session.Tags)].Value())
+					if errors.Is(err, session.ErrQueued) {
+						e.Reset()
+						return backToMain(e.commonModel, err)
+					}
+					if err != nil {
+						e.err = err
+						return e, nil
+					}
 					e.Reset()
 					return NewMainModel(e.dump, e.session, e.state)
 				}
@@ -233,17 +249,24 @@ func (e editModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				if len(tags) != 0 {
 					endDatetime := e.inputs[indexEndDatetime].Value()
-					updated_task := e.task.Update(
+					updated_task, err := e.task.Update(
 						e.inputs[indexStartDatetime].Value(),
 						endDatetime,
 						e.inputs[indexNote].Value(),
 						tags,
 					)
+					if err != nil {
+						e.err = err
+						return e, nil
+					}
 
-					if endDatetime == "" {
-						e.session.UpdateTimerTask(updated_task.(session.TimerTask))
-					} else {
-						e.session.UpdateTimeSpanTask(updated_task.(session.TimeSpanTask))
+					err = e.session.UpdateTaskFrom(e.task, updated_task)
+					if errors.Is(err, session.ErrQueued) {
+						return backToMain(e.commonModel, err)
+					}
+					if err != nil {
+						e.err = err
+						return e, nil
 					}
 					return NewMainModel(e.dump, e.session, e.state)
 				}
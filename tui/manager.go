@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
@@ -17,9 +18,13 @@ import (
 	session "github.com/kalidor/traggo_cli/session"
 )
 
-var baseStyle = lipgloss.NewStyle().
-	BorderStyle(lipgloss.NormalBorder()).
-	BorderForeground(lipgloss.Color("240"))
+var (
+	baseStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("240"))
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	infoStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+)
 
 type sessionState int
 
@@ -57,18 +62,39 @@ type mainModel struct {
 	currentTask   string
 	cursor        int
 	searchCase    int
+	err           error
 }
 
-func (m mainModel) getTasks(withComplete bool) []table.Row {
-	rows := m.session.ListCurrentTasks().ToBubbleRow()
-	if withComplete {
-		// Add complete Tasks
-		rows = append(rows, m.session.ListCompleteTasks().ToBubbleRow()...)
+func getTasks(s *session.Traggo, withComplete bool) ([]table.Row, error) {
+	if !withComplete {
+		timers, err := s.ListCurrentTasks()
+		if err != nil {
+			return nil, err
+		}
+		return timers.ToBubbleRow(), nil
 	}
-	return rows
+	timers, tasks, err := s.AllTasks()
+	if err != nil {
+		return nil, err
+	}
+	return append(timers.ToBubbleRow(), tasks.ToBubbleRow()...), nil
+}
+
+// backToMain goes back to the main view and display err if any
+func backToMain(c commonModel, err error) (tea.Model, tea.Cmd) {
+	m := newMainModel(c.dump, c.session, TableView)
+	if err != nil {
+		m.err = err
+	}
+	return m, nil
 }
 
 func NewMainModel(dump io.Writer, session *session.Traggo, state sessionState) (tea.Model, tea.Cmd) {
+	m := newMainModel(dump, session, state)
+	return m, func() tea.Msg { return errMsg{nil} }
+}
+
+func newMainModel(dump io.Writer, session *session.Traggo, state sessionState) mainModel {
 	columns := []table.Column{
 		{Title: "Id", Width: 4},
 		{Title: "Tags", Width: 30},
@@ -77,9 +103,7 @@ func NewMainModel(dump io.Writer, session *session.Traggo, state sessionState) (
 		{Title: "Time", Width: 10},
 		{Title: "Notes", Width: 40},
 	}
-	rows := session.ListCurrentTasks().ToBubbleRow()
-	// Add complete Tasks
-	rows = append(rows, session.ListCompleteTasks().ToBubbleRow()...)
+	rows, err := getTasks(session, true)
 	m := mainModel{
 		keys:        mainKeys,
 		help:        help.New(),
@@ -93,8 +117,9 @@ func NewMainModel(dump io.Writer, session *session.Traggo, state sessionState) (
 			session: session,
 			state:   state,
 		},
+		err: err,
 	}
-	return m, func() tea.Msg { return errMsg{nil} }
+	return m
 }
 
 func (m mainModel) Init() tea.Cmd {
@@ -102,12 +127,36 @@ func (m mainModel) Init() tea.Cmd {
 }
 
 func (m *mainModel) Refresh() {
-	m.rowsOrigin = m.getTasks(true)
+	rows, err := getTasks(m.session, true)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.rowsOrigin = rows
 	m.table.SetRows(m.rowsOrigin)
 	m.lastRefreshed = time.Now().Local().Format(time.DateTime)
 
 }
 
+// showSelectedTask fills currentTask with the details of the selected row
+func (m *mainModel) showSelectedTask() {
+	current_row := m.table.SelectedRow()
+	if current_row == nil {
+		return
+	}
+	task_id, _ := strconv.Atoi(current_row[0])
+	task, err := m.session.SearchTask(task_id)
+	if err != nil {
+		m.err = err
+		return
+	}
+	if task == nil {
+		m.err = fmt.Errorf("unable to retrieve task id %d", task_id)
+		return
+	}
+	m.currentTask = task.PreparePretty(m.session.Colors)
+}
+
 // func (m *mainModel) updateDimensions(width, height int) {
 // 	m.width = width
 // 	m.height = height
@@ -222,8 +271,14 @@ func (m *mainModel) searchByPeriodInRows() {
 	endDate := time.Now()
 	// period is negative number
 	delta(endDate, &startDate)
-	tasks := m.session.ListBetweenDates(startDate, endDate)
-	m.table.SetRows(tasks.ToBubbleRow())
+	tasks, err := m.session.SearchTasks(session.TaskFilter{From: startDate, To: endDate})
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.err = nil
+	timers, spans := session.SplitTasks(tasks)
+	m.table.SetRows(append(timers.ToBubbleRow(), spans.ToBubbleRow()...))
 }
 
 func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -235,6 +290,13 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 	var cmd tea.Cmd
 
+	if msg, ok := msg.(errMsg); ok {
+		if msg.error != nil {
+			m.err = msg.error
+		}
+		return m, cmd
+	}
+
 	switch m.state {
 
 	case periodView:
@@ -310,6 +372,8 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// 	m.updateDimensions(msg.Width, msg.Height)
 		// 	return m, nil
 		case tea.KeyMsg:
+			// a new action has been requested, forget about the previous error
+			m.err = nil
 			switch msg.String() {
 			case "ctrl+w":
 				// Remove last search term
@@ -332,45 +396,25 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "pgup":
 				m.table.MoveUp(10)
 				if m.currentTask != "" {
-					current_row := m.table.SelectedRow()
-					if current_row == nil {
-						return m, cmd
-					}
-					task_id, _ := strconv.Atoi(current_row[0])
-					m.currentTask = m.session.SearchTask(task_id).PreparePretty(m.session.Colors)
+					(&m).showSelectedTask()
 				}
 				return m, cmd
 			case "pgdown":
 				m.table.MoveDown(10)
 				if m.currentTask != "" {
-					current_row := m.table.SelectedRow()
-					if current_row == nil {
-						return m, cmd
-					}
-					task_id, _ := strconv.Atoi(current_row[0])
-					m.currentTask = m.session.SearchTask(task_id).PreparePretty(m.session.Colors)
+					(&m).showSelectedTask()
 				}
 				return m, cmd
 			case "up":
 				m.table.MoveUp(1)
 				if m.currentTask != "" {
-					current_row := m.table.SelectedRow()
-					if current_row == nil {
-						return m, cmd
-					}
-					task_id, _ := strconv.Atoi(current_row[0])
-					m.currentTask = m.session.SearchTask(task_id).PreparePretty(m.session.Colors)
+					(&m).showSelectedTask()
 				}
 				return m, cmd
 			case "down":
 				m.table.MoveDown(1)
 				if m.currentTask != "" {
-					current_row := m.table.SelectedRow()
-					if current_row == nil {
-						return m, cmd
-					}
-					task_id, _ := strconv.Atoi(current_row[0])
-					m.currentTask = m.session.SearchTask(task_id).PreparePretty(m.session.Colors)
+					(&m).showSelectedTask()
 				}
 				return m, cmd
 			case "q", "ctrl+c", "esc":
@@ -384,28 +428,63 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "/": // search Task / Filter
 				m.state = searchView
 			case "n": // add new Task
-				return initEdit(m.dump, m.session, m.state, "-1").Update(msg)
+				e, err := initEdit(m.dump, m.session, m.state, "-1")
+				if err != nil {
+					m.err = err
+					return m, cmd
+				}
+				return e.Update(msg)
 
 			case "d": // delete Task
 				current_row := m.table.SelectedRow()
 				if current_row == nil {
 					return m, cmd
 				}
-				return initDelete(m.dump, m.session, m.state, current_row[0]).Update(msg)
+				d, err := initDelete(m.dump, m.session, m.state, current_row[0])
+				if err != nil {
+					m.err = err
+					return m, cmd
+				}
+				return d.Update(msg)
 
-			case "e", "u": // edit & update
+			case "u": // undo
+				_, err := m.session.Undo(1)
+				if err != nil {
+					m.err = err
+					return m, cmd
+				}
+				m.Refresh()
+			case "e": // edit & update
 				current_row := m.table.SelectedRow()
 				if current_row == nil {
 					return m, cmd
 				}
-				return initEdit(m.dump, m.session, m.state, current_row[0]).Update(msg)
+				e, err := initEdit(m.dump, m.session, m.state, current_row[0])
+				if err != nil {
+					m.err = err
+					return m, cmd
+				}
+				return e.Update(msg)
 			case "c": // continue
 				current_row := m.table.SelectedRow()
 				if current_row == nil {
 					return m, cmd
 				}
 				taskId, _ := strconv.Atoi(current_row[0])
-				m.session.Continue(m.session.SearchTask(taskId))
+				task, err := m.session.SearchTask(taskId)
+				if err != nil {
+					m.err = err
+					return m, cmd
+				}
+				if task == nil {
+					m.err = fmt.Errorf("unable to retrieve task id %d", taskId)
+					return m, cmd
+				}
+				err = m.session.Continue(task)
+				if err != nil {
+					m.err = err
+					return m, cmd
+				}
 				m.Refresh()
 			case "s": // stop
 				current_row := m.table.SelectedRow()
@@ -413,10 +492,15 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, cmd
 				}
 				taskId, _ := strconv.Atoi(current_row[0])
-				m.session.Stop(m.session.Colors, []int{taskId})
+				_, err := m.session.Stop([]int{taskId})
+				if err != nil {
+					m.err = err
+					return m, cmd
+				}
 				m.Refresh()
 			case "r": // refresh
 				m.searchStrings = []string{}
+				m.session.Cache.Invalidate()
 				m.Refresh()
 
 			case "?":
@@ -427,12 +511,7 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.currentTask != "" {
 					m.currentTask = ""
 				} else {
-					current_row := m.table.SelectedRow()
-					if current_row == nil {
-						return m, cmd
-					}
-					task_id, _ := strconv.Atoi(current_row[0])
-					m.currentTask = m.session.SearchTask(task_id).PreparePretty(m.session.Colors)
+					(&m).showSelectedTask()
 				}
 			}
 		}
@@ -457,11 +536,18 @@ func (m mainModel) View() string {
 		searchTerms = ""
 		m.searchStrings = []string{}
 	}
+	errView := ""
+	if errors.Is(m.err, session.ErrQueued) {
+		// the operation is not lost, it is sent by the next sync
+		errView = infoStyle.Render("Traggo is unreachable: operation queued, run 'traggo_cli sync' once back online") + "\n"
+	} else if m.err != nil {
+		errView = errorStyle.Render(fmt.Sprintf("Error: %s", m.err)) + "\n"
+	}
 	switch m.state {
 	case searchView:
-		return baseStyle.Render(m.table.View()) + "\n" + m.searchInput.View() + searchTerms + "\n" + searchHelpView
+		return baseStyle.Render(m.table.View()) + "\n" + m.searchInput.View() + searchTerms + "\n" + errView + searchHelpView
 	case periodView:
-		return baseStyle.Render(m.table.View()) + "\n" + m.periodInput.View() + periodTerms + "\n" + searchHelpView
+		return baseStyle.Render(m.table.View()) + "\n" + m.periodInput.View() + periodTerms + "\n" + errView + searchHelpView
 	}
 	if m.currentTask != "" {
 		m.currentTask = fmt.Sprintf("%s\n", m.currentTask)
@@ -470,7 +556,7 @@ func (m mainModel) View() string {
 		m.lastRefreshed = fmt.Sprintf("Refreshed: %s\n", m.lastRefreshed)
 	}
 
-	return baseStyle.Render(m.table.View()) + "\n" + m.currentTask + searchTerms + periodTerms + "\n" + m.lastRefreshed + helpView
+	return baseStyle.Render(m.table.View()) + "\n" + m.currentTask + searchTerms + periodTerms + "\n" + errView + m.lastRefreshed + helpView
 
 }
 
@@ -6,6 +6,7 @@ import "github.com/charmbracelet/bubbles/key"
 // key.Map. It could also very easily be a map[string]key.Binding.
 type mainKeyMap struct {
 	E     key.Binding // Edit
+	U     key.Binding // Undo
 	R     key.Binding // Refresh
 	C     key.Binding // Continue
 	D     key.Binding // Delete
@@ -30,9 +31,9 @@ func (k mainKeyMap) ShortHelp() []key.Binding {
 // key.Map interface.
 func (k mainKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.N, k.S, k.Up, k.Down}, // first column
-		{k.C, k.D, k.E, k.R},     // second column
-		{k.Help, k.Quit},         // third column
+		{k.N, k.S, k.Up, k.Down},  // first column
+		{k.C, k.D, k.E, k.U, k.R}, // second column
+		{k.Help, k.Quit},          // third column
 	}
 }
 
@@ -49,6 +50,10 @@ var mainKeys = mainKeyMap{
 		key.WithKeys("e"),
 		key.WithHelp("e", "edit"),
 	),
+	U: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "undo"),
+	),
 	Enter: key.NewBinding(
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "show/hide"),
@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	session "github.com/kalidor/traggo_cli/session"
+	"golang.org/x/term"
+)
+
+// DefaultPickLimit is the number of recent tasks proposed by PickTasks
+const DefaultPickLimit = 200
+
+var (
+	pickerCursorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("229")).
+				Background(lipgloss.Color("57"))
+	pickerSelectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("212"))
+	pickerCountStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+)
+
+// Picker is a fuzzy finder choosing one or several tasks. Each word typed
+// must match the id, tags, start or note of a task, its letters in order.
+// Best matches come first, then the most recent tasks.
+type Picker struct {
+	keys      pickerKeyMap
+	help      help.Model
+	input     textinput.Model
+	tasks     []session.GenericTask
+	texts     []string     // searched text of each task, in lower case
+	lines     []string     // displayed line of each task
+	matches   []int        // indexes of the tasks matching the input, best first
+	cursor    int          // in matches
+	chosen    map[int]bool // indexes of the tasks selected with tab
+	multi     bool
+	height    int // number of displayed tasks
+	done      bool
+	cancelled bool
+}
+
+// NewPicker proposes tasks, in this order without input.
+// Several tasks can be chosen if multi is set.
+func NewPicker(tasks []session.GenericTask, multi bool) Picker {
+	input := textinput.New()
+	input.Placeholder = "Search id, tags, date or note"
+	input.Prompt = "> "
+	input.Focus()
+
+	keys := pickerKeys
+	keys.Toggle.SetEnabled(multi)
+	keys.All.SetEnabled(multi)
+	m := Picker{
+		keys:   keys,
+		help:   help.New(),
+		input:  input,
+		tasks:  tasks,
+		chosen: map[int]bool{},
+		multi:  multi,
+		height: 10,
+	}
+	for _, task := range tasks {
+		line := pickerLine(task)
+		m.lines = append(m.lines, line)
+		m.texts = append(m.texts, strings.ToLower(line))
+	}
+	m.filter()
+	return m
+}
+
+// pickerLine describes task on a single line
+func pickerLine(task session.GenericTask) string {
+	var tags []session.Tag
+	var duration string
+	switch task := task.(type) {
+	case session.TimerTask:
+		tags = task.Tags
+		duration = fmt.Sprintf("running %s", session.TimeNow().Sub(task.Start).Round(time.Minute))
+	case session.TimeSpanTask:
+		tags = task.Tags
+		duration = task.End.Sub(task.Start).Round(time.Minute).String()
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Key + ":" + tag.Value
+	}
+	return fmt.Sprintf("%-6d %-40s %s  %-16s %s", task.GetId(), strings.Join(names, ","), task.GetStart().Local().Format(time.DateTime), duration, task.GetNote())
+}
+
+// fuzzyScore tells if the letters of pattern appear in text in this order,
+// and scores the match: consecutive letters and word starts score more
+func fuzzyScore(pattern, text string) (int, bool) {
+	score, p, last := 0, []rune(pattern), -2
+	if len(p) == 0 {
+		return 0, true
+	}
+	runes := []rune(text)
+	for i, r := range runes {
+		if r != p[0] {
+			continue
+		}
+		score++
+		if last == i-1 {
+			score += 2
+		}
+		if i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]) {
+			score++
+		}
+		last = i
+		p = p[1:]
+		if len(p) == 0 {
+			return score, true
+		}
+	}
+	return 0, false
+}
+
+// filter updates matches from the input
+func (m *Picker) filter() {
+	words := strings.Fields(strings.ToLower(m.input.Value()))
+	scores := map[int]int{}
+	m.matches = m.matches[:0]
+	for i, text := range m.texts {
+		total, ok := 0, true
+		for _, word := range words {
+			var score int
+			score, ok = fuzzyScore(word, text)
+			if !ok {
+				break
+			}
+			total += score
+		}
+		if ok {
+			scores[i] = total
+			m.matches = append(m.matches, i)
+		}
+	}
+	sort.SliceStable(m.matches, func(i, j int) bool {
+		return scores[m.matches[i]] > scores[m.matches[j]]
+	})
+	m.cursor = max(0, min(m.cursor, len(m.matches)-1))
+}
+
+// Selected returns the chosen tasks, in the order they were proposed.
+// Nothing is returned if the picker was cancelled.
+func (m Picker) Selected() []session.GenericTask {
+	if m.cancelled || !m.done {
+		return nil
+	}
+	var selected []session.GenericTask
+	for i, task := range m.tasks {
+		if m.chosen[i] {
+			selected = append(selected, task)
+		}
+	}
+	if len(selected) == 0 && len(m.matches) > 0 {
+		selected = append(selected, m.tasks[m.matches[m.cursor]])
+	}
+	return selected
+}
+
+func (m Picker) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// input, count and help take 4 lines
+		m.height = max(1, msg.Height-4)
+		return m, nil
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Esc):
+			m.cancelled = true
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Enter):
+			m.done = true
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Up):
+			m.cursor = max(0, m.cursor-1)
+			return m, nil
+		case key.Matches(msg, m.keys.Down):
+			m.cursor = max(0, min(m.cursor+1, len(m.matches)-1))
+			return m, nil
+		case key.Matches(msg, m.keys.Toggle):
+			if len(m.matches) > 0 {
+				i := m.matches[m.cursor]
+				if m.chosen[i] {
+					delete(m.chosen, i)
+				} else {
+					m.chosen[i] = true
+				}
+				m.cursor = min(m.cursor+1, len(m.matches)-1)
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.All):
+			for _, i := range m.matches {
+				m.chosen[i] = true
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.filter()
+	return m, cmd
+}
+
+func (m Picker) View() string {
+	var b strings.Builder
+	b.WriteString(m.input.View() + "\n")
+	// keep the cursor visible
+	first := max(0, m.cursor-m.height+1)
+	for k := first; k < len(m.matches) && k < first+m.height; k++ {
+		i := m.matches[k]
+		mark := "  "
+		if m.chosen[i] {
+			mark = "* "
+		}
+		line := mark + m.lines[i]
+		switch {
+		case k == m.cursor:
+			line = pickerCursorStyle.Render(line)
+		case m.chosen[i]:
+			line = pickerSelectedStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	count := fmt.Sprintf("%d/%d", len(m.matches), len(m.tasks))
+	if len(m.chosen) > 0 {
+		count += fmt.Sprintf(" (%d selected)", len(m.chosen))
+	}
+	b.WriteString(pickerCountStyle.Render(count) + "\n")
+	b.WriteString(m.help.View(m.keys))
+	return b.String()
+}
+
+// Pick lets the user choose among tasks on the terminal, see Picker.
+// Nothing is returned if the user cancels.
+func Pick(tasks []session.GenericTask, multi bool) ([]session.GenericTask, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, errors.New("no terminal to pick a task, give its id")
+	}
+	if len(tasks) == 0 {
+		return nil, errors.New("no task to pick")
+	}
+	// stdout is kept for the output of the command
+	p := tea.NewProgram(NewPicker(tasks, multi), tea.WithOutput(os.Stderr))
+	final, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	return final.(Picker).Selected(), nil
+}
+
+// PickTasks lets the user choose among the most recent tasks selected by
+// filter, DefaultPickLimit of them if filter has no limit
+func PickTasks(ctx context.Context, s *session.Traggo, filter session.TaskFilter, multi bool) ([]session.GenericTask, error) {
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPickLimit
+	}
+	tasks, err := s.SearchTasksContext(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return Pick(tasks, multi)
+}
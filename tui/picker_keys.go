@@ -0,0 +1,53 @@
+package tui
+
+import "github.com/charmbracelet/bubbles/key"
+
+type pickerKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Toggle key.Binding // only with several tasks to pick
+	All    key.Binding // only with several tasks to pick
+	Enter  key.Binding
+	Esc    key.Binding
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k pickerKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Toggle, k.All, k.Enter, k.Esc}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k pickerKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Toggle, k.All, k.Enter, k.Esc},
+	}
+}
+
+var pickerKeys = pickerKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "ctrl+p"),
+		key.WithHelp("↑/Ctrl+p", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "ctrl+n"),
+		key.WithHelp("↓/Ctrl+n", "down"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "select"),
+	),
+	All: key.NewBinding(
+		key.WithKeys("ctrl+a"),
+		key.WithHelp("Ctrl+a", "select all matches"),
+	),
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("Enter", "validate"),
+	),
+	Esc: key.NewBinding(
+		key.WithKeys("esc", "ctrl+c"),
+		key.WithHelp("Esc", "cancel"),
+	),
+}
@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateHelp describes the forms accepted by ParseDate, for flag usages
+const DateHelp = "YYYY-MM-DD[ hh:mm[:ss]], hh:mm, today|yesterday|tomorrow|[last ]monday[ hh:mm], 2h ago, -1d, 2025-W05"
+
+// precision of a parsed date, telling how long is the period it designates
+type precision int
+
+const (
+	instant precision = iota
+	day
+	week
+)
+
+// absoluteLayouts are tried in order, in local time unless a zone is given
+var absoluteLayouts = []struct {
+	layout    string
+	precision precision
+}{
+	{time.RFC3339, instant},
+	{"2006-01-02T15:04:05", instant},
+	{time.DateTime, instant},
+	{"2006-01-02T15:04", instant},
+	{"2006-01-02 15:04", instant},
+	{time.DateOnly, day},
+}
+
+var (
+	relativeRe = regexp.MustCompile(`^(in |\+|-)?\s*((?:\d+\s*(?:w|d|h|m|min|s)\s*)+?)\s*(ago)?$`)
+	durationRe = regexp.MustCompile(`(\d+)\s*(w|d|h|min|m|s)`)
+	isoWeekRe  = regexp.MustCompile(`^(?:(\d{4})-?)?w(\d{1,2})(?:-([1-7]))?$`)
+	clockRe    = regexp.MustCompile(`^(\d{1,2}):(\d{2})(?::(\d{2}))?$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseDate converts s to a time in the local time zone. Accepted forms are:
+//   - absolute dates: 2025-12-01, 2025-12-01 14:00, 2025-12-01 14:00:05, RFC3339
+//   - a time of today: 09:30, 09:30:15
+//   - a day relative to today, with an optional time: today, yesterday 14:00,
+//     tomorrow, monday (the last monday, today included), last friday 9:00
+//     (today excluded)
+//   - a duration relative to now: 2h ago, 1h30m ago, 3d ago, -15m, +1w, in 2h
+//   - an ISO week, starting on monday: 2025-W05, W05 (this year), 2025-W05-3 (wednesday)
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	d, _, err := parseDate(s, now)
+	return d, err
+}
+
+// ParseDatePeriod returns the beginning and the end of the period designated
+// by s: a whole day for a date without time, a whole week for an ISO week.
+// Both are equal for a date with a time.
+func ParseDatePeriod(s string, now time.Time) (time.Time, time.Time, error) {
+	d, p, err := parseDate(s, now)
+	if err != nil {
+		return d, d, err
+	}
+	switch p {
+	case day:
+		return d, d.AddDate(0, 0, 1), nil
+	case week:
+		return d, d.AddDate(0, 0, 7), nil
+	}
+	return d, d, nil
+}
+
+// ParseDuration is time.ParseDuration also accepting days (d) and weeks (w)
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	if strings.Trim(durationRe.ReplaceAllString(s, ""), " ") != "" || s == "" {
+		return 0, fmt.Errorf("invalid duration '%s'", s)
+	}
+	var total time.Duration
+	for _, m := range durationRe.FindAllStringSubmatch(s, -1) {
+		n, _ := strconv.Atoi(m[1])
+		unit := map[string]time.Duration{
+			"w": 7 * 24 * time.Hour, "d": 24 * time.Hour, "h": time.Hour,
+			"m": time.Minute, "min": time.Minute, "s": time.Second,
+		}[m[2]]
+		total += time.Duration(n) * unit
+	}
+	return total, nil
+}
+
+// ParseClock converts a time of day (hh:mm or hh:mm:ss) to the duration since midnight
+func ParseClock(s string) (time.Duration, error) {
+	m := clockRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid time '%s', expected hh:mm", s)
+	}
+	h, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	sec := 0
+	if m[3] != "" {
+		sec, _ = strconv.Atoi(m[3])
+	}
+	if h > 24 || minute > 59 || sec > 59 || h == 24 && minute+sec > 0 {
+		return 0, fmt.Errorf("invalid time '%s', expected hh:mm", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(sec)*time.Second, nil
+}
+
+func parseDate(input string, now time.Time) (time.Time, precision, error) {
+	now = now.In(time.Local)
+	s := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	invalid := fmt.Errorf("invalid date '%s', expected %s", input, DateHelp)
+	if s == "" {
+		return time.Time{}, instant, invalid
+	}
+	if s == "now" {
+		return now, instant, nil
+	}
+
+	for _, l := range absoluteLayouts {
+		d, err := time.ParseInLocation(l.layout, strings.ToUpper(s), time.Local)
+		if err == nil {
+			return d, l.precision, nil
+		}
+	}
+
+	if m := relativeRe.FindStringSubmatch(s); m != nil && (m[1] != "" || m[3] != "") {
+		if m[1] != "" && m[3] != "" {
+			return time.Time{}, instant, invalid
+		}
+		d, err := ParseDuration(m[2])
+		if err != nil {
+			return time.Time{}, instant, invalid
+		}
+		if m[1] == "-" || m[3] == "ago" {
+			d = -d
+		}
+		return now.Add(d), instant, nil
+	}
+
+	if m := isoWeekRe.FindStringSubmatch(s); m != nil {
+		year := now.Year()
+		if m[1] != "" {
+			year, _ = strconv.Atoi(m[1])
+		}
+		w, _ := strconv.Atoi(m[2])
+		// the 28th of december is always in the last ISO week
+		_, weeks := time.Date(year, time.December, 28, 0, 0, 0, 0, time.Local).ISOWeek()
+		if w < 1 || w > weeks {
+			return time.Time{}, instant, invalid
+		}
+		// the 4th of january is always in the first ISO week
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.Local)
+		monday := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(w-1)*7)
+		if m[3] == "" {
+			return monday, week, nil
+		}
+		n, _ := strconv.Atoi(m[3])
+		return monday.AddDate(0, 0, n-1), day, nil
+	}
+
+	// day word and/or time of day
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	words := strings.Split(s, " ")
+	clock := ""
+	if clockRe.MatchString(words[len(words)-1]) {
+		clock = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	base := today
+	switch dayWord := strings.Join(words, " "); dayWord {
+	case "", "today":
+	case "yesterday":
+		base = today.AddDate(0, 0, -1)
+	case "tomorrow":
+		base = today.AddDate(0, 0, 1)
+	default:
+		last := false
+		if rest, ok := strings.CutPrefix(dayWord, "last "); ok {
+			last, dayWord = true, rest
+		}
+		wd, ok := weekdays[dayWord]
+		if !ok {
+			return time.Time{}, instant, invalid
+		}
+		back := (int(today.Weekday()) - int(wd) + 7) % 7
+		if back == 0 && last {
+			back = 7
+		}
+		base = today.AddDate(0, 0, -back)
+	}
+	if clock == "" {
+		return base, day, nil
+	}
+	m := clockRe.FindStringSubmatch(clock)
+	h, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	sec := 0
+	if m[3] != "" {
+		sec, _ = strconv.Atoi(m[3])
+	}
+	if h > 23 || minute > 59 || sec > 59 {
+		return time.Time{}, instant, invalid
+	}
+	return time.Date(base.Year(), base.Month(), base.Day(), h, minute, sec, 0, time.Local), instant, nil
+}
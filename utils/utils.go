@@ -9,6 +9,8 @@ import (
 )
 
 // StrToTime convert string to time.Time with the provided layout
+//
+// Deprecated: the result is in UTC, use ParseDate which accepts more forms in local time.
 func StrToTime(input string, layout string) (time.Time, error) {
 	r, err := time.Parse(layout, input)
 	if err != nil {
@@ -17,6 +19,18 @@ func StrToTime(input string, layout string) (time.Time, error) {
 	return r, nil
 }
 
+// Ask prints prompt and returns the line typed by the user, without spaces around.
+// Don't use this for password input.
+func Ask(prompt string) (string, error) {
+	fmt.Println(prompt)
+	reader := bufio.NewReader(os.Stdin)
+	userInput, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(userInput), nil
+}
+
 // AskAndCompare will print prompt and compare user's input
 // with expected response provided.
 // Don't use this for password input.